@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/ianmarmour/nvidia-clerk/internal/alert"
@@ -12,97 +17,112 @@ import (
 	"github.com/ianmarmour/nvidia-clerk/internal/rest"
 )
 
+// shutdownTimeout Maximum time to wait for pending notifications and servers once asked to stop.
+const shutdownTimeout = 15 * time.Second
+
+// regionDelay Pause between starting each region to avoid rate limiting.
+const regionDelay = 10 * time.Second
+
 func main() {
+	// Cancelled by the first SIGINT or SIGTERM, a second one exits straight away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	enabled := map[string]*bool{}
+	for _, channel := range alert.Channels() {
+		enabled[channel.Name] = flag.Bool(channel.Name, channel.Name == "discord", channel.Usage)
+	}
+	flag.Parse()
+
+	// API status changes go to every enabled channel, product notifications only to Discord.
+	notifiers := []string{}
+	for _, channel := range alert.Channels() {
+		if *enabled[channel.Name] == true {
+			notifiers = append(notifiers, channel.Name)
+		}
+	}
+
 	var wg sync.WaitGroup
-	wg.Add(len(config.RegionalConfigs) + 2)
 
-	cfg, err := config.Get("USA", "2060", 1, false, true, false, false, false, true, false)
+	cfg, err := config.Get([]string{"USA"}, []string{"2060"}, time.Millisecond, notifiers, true, false)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	wg.Add(2)
+
 	// Monitor a single region for the session
-	go alert.StartDiscordAPINotifications("USA", "session", *cfg, &wg)
+	go alert.StartAPINotifications(ctx, "USA", "session", *cfg, &wg)
 
 	// Monitor only USA for the shields API sorry other regions.
-	go rest.StartShieldsAPIServer(*cfg.ShieldsConfig, &wg)
-
-	// Setup Notifications for all other regions avoiding rate limiting.
-	for id := range config.RegionalConfigs {
-		time.Sleep(10 * time.Second)
-		tempID := id
-		c, err := config.Get(tempID, "2060", 1, false, true, false, false, false, false, false)
-		if err != nil {
-			log.Println(fmt.Sprintf("Error getting configuration for %s", tempID))
-			wg.Add(-1)
-			continue
-		}
+	go rest.StartShieldsAPIServer(ctx, *cfg.ShieldsConfig, rest.NewClient(cfg.APIConfig, &http.Client{Timeout: 10 * time.Second}), &wg)
+
+	// Setup Notifications for all other regions, then 3080 and 3090 Product Notifications for all regions.
+	regional := []struct {
+		model string
+		start func(region string, c config.Config)
+	}{
+		{"2060", func(region string, c config.Config) {
+			alert.StartAPINotifications(ctx, region, "checkout", c, &wg)
+		}},
+		{"3080", func(region string, c config.Config) { alert.StartDiscordProductNotifications(ctx, "3080", c, &wg) }},
+		{"3090", func(region string, c config.Config) { alert.StartDiscordProductNotifications(ctx, "3090", c, &wg) }},
+	}
 
-		dcURL, dcURLOK := os.LookupEnv(fmt.Sprintf("DISCORD_WEBHOOK_URL_%s", tempID))
-		if dcURLOK == false {
-			log.Println(fmt.Sprintf("Error getting discord webhook configuration for %s", tempID))
-			wg.Add(-1)
-			continue
+	for _, r := range regional {
+		if startRegions(ctx, r.model, notifiers, &wg, r.start) != nil {
+			break
 		}
+	}
 
-		cc := *c
-		cc.DiscordConfig = &config.DiscordConfig{WebhookURL: dcURL}
-
-		log.Println(fmt.Sprintf("Starting goroutine for %s", tempID))
-		go alert.StartDiscordAPINotifications(tempID, "checkout", cc, &wg)
+	<-ctx.Done()
+	stop()
+	log.Println(fmt.Sprintf("Shutting down, waiting up to %s for pending notifications...", shutdownTimeout))
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("Shutdown complete")
+	case <-time.After(shutdownTimeout):
+		log.Println("Timed out waiting for shutdown exiting...")
+		os.Exit(1)
 	}
+}
 
-	wg.Add(len(config.RegionalConfigs))
-	// Setup 3080 Product Notifications for all regions
+// startRegions Runs start in a goroutine for every region with a Discord webhook, pausing between regions to avoid rate limiting until ctx is done.
+func startRegions(ctx context.Context, model string, notifiers []string, wg *sync.WaitGroup, start func(region string, cfg config.Config)) error {
 	for id := range config.RegionalConfigs {
-		time.Sleep(10 * time.Second)
-		tempID := id
-		c, err := config.Get(tempID, "3080", 1, false, true, false, false, false, false, false)
+		err := rest.Sleep(ctx, regionDelay)
 		if err != nil {
-			log.Println(fmt.Sprintf("Error getting configuration for %s", tempID))
-			wg.Add(-1)
-			continue
+			return err
 		}
 
-		dcURL, dcURLOK := os.LookupEnv(fmt.Sprintf("DISCORD_WEBHOOK_URL_%s", tempID))
-		if dcURLOK == false {
-			log.Println(fmt.Sprintf("Error getting discord webhook configuration for %s", tempID))
-			wg.Add(-1)
-			continue
-		}
-
-		cc := *c
-		cc.DiscordConfig = &config.DiscordConfig{WebhookURL: dcURL}
-
-		log.Println(fmt.Sprintf("Starting goroutine for %s", tempID))
-		go alert.StartDiscordProductNotifications("3080", cc, &wg)
-	}
-
-	wg.Add(len(config.RegionalConfigs))
-	// Setup 3090 Product Notifications for all regions
-	for id := range config.RegionalConfigs {
-		time.Sleep(10 * time.Second)
-		tempID := id
-		c, err := config.Get(tempID, "3090", 1, false, true, false, false, false, false, false)
+		c, err := config.Get([]string{id}, []string{model}, time.Millisecond, notifiers, false, false)
 		if err != nil {
-			log.Println(fmt.Sprintf("Error getting configuration for %s", tempID))
-			wg.Add(-1)
+			log.Println(fmt.Sprintf("Error getting configuration for %s", id))
 			continue
 		}
 
-		dcURL, dcURLOK := os.LookupEnv(fmt.Sprintf("DISCORD_WEBHOOK_URL_%s", tempID))
+		dcURL, dcURLOK := os.LookupEnv(fmt.Sprintf("DISCORD_WEBHOOK_URL_%s", id))
 		if dcURLOK == false {
-			log.Println(fmt.Sprintf("Error getting discord webhook configuration for %s", tempID))
-			wg.Add(-1)
+			log.Println(fmt.Sprintf("Error getting discord webhook configuration for %s", id))
 			continue
 		}
 
-		cc := *c
-		cc.DiscordConfig = &config.DiscordConfig{WebhookURL: dcURL}
+		if c.Notifiers == nil {
+			c.Notifiers = map[string]interface{}{}
+		}
+		c.Notifiers["discord"] = &alert.DiscordConfig{WebhookURL: dcURL}
 
-		log.Println(fmt.Sprintf("Starting goroutine for %s", tempID))
-		go alert.StartDiscordProductNotifications("3090", cc, &wg)
+		log.Println(fmt.Sprintf("Starting goroutine for %s", id))
+		wg.Add(1)
+		go start(id, *c)
 	}
 
-	wg.Wait()
+	return nil
 }
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/fakestore"
+)
+
+func main() {
+	script := flag.String("script", "", "Path to a YAML script of the fake stores products and inventory timelines.")
+	addr := flag.String("addr", "localhost:8080", "Address to serve the fake store on.")
+	flag.Parse()
+
+	if *script == "" {
+		log.Fatal("-script is required E.X. -script=internal/fakestore/testdata/restock.yaml")
+	}
+
+	s, err := fakestore.ReadScript(*script)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println(fmt.Sprintf("Fake store serving %d products on http://%s", len(s.Products), *addr))
+	log.Fatal(http.ListenAndServe(*addr, fakestore.New(*s, nil)))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/discover"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+// runDiscover Queries NVIDIAs API for the models sold in each region and emits them as a catalog.
+func runDiscover(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("discover", flag.ExitOnError)
+	region := flags.String("region", "", "Comma separated 3 Letter region codes E.X. DEU or DEU,AUT, defaults to every catalog region")
+	locale := flags.String("locale", "", "API locale E.X. de_de, required for regions missing from the catalog")
+	nvidiaLocale := flags.String("nvidia-locale", "", "Website locale E.X. de-de, required for regions missing from the catalog")
+	currency := flags.String("currency", "", "Currency code E.X. EUR, required for regions missing from the catalog")
+	catalog := flags.String("catalog", "", "Path or URL of a JSON catalog used to look up region locales and currencies.")
+	output := flags.String("output", "", "Path to write the discovered catalog to, defaults to stdout.")
+	apiURL := flags.String("api-url", "", "Base URL of NVIDIAs product API E.X. a local stand-in server, defaults to NVIDIAs production API.")
+	flags.Parse(args)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	if *catalog != "" {
+		c, err := config.LoadCatalog(*catalog, client)
+		if err != nil {
+			return err
+		}
+		config.SetCatalog(c)
+	}
+
+	codes := config.SplitList(*region)
+	if len(codes) == 0 {
+		for code := range config.RegionalConfigs {
+			codes = append(codes, code)
+		}
+	}
+
+	regions := map[string]config.RegionalConfig{}
+	for _, code := range codes {
+		rc := config.RegionalConfigs[code]
+
+		if *locale != "" {
+			rc.Locale = *locale
+		}
+		if *nvidiaLocale != "" {
+			rc.NvidiaLocale = *nvidiaLocale
+		}
+		if *currency != "" {
+			rc.Currency = *currency
+		}
+
+		if rc.Locale == "" || rc.NvidiaLocale == "" || rc.Currency == "" {
+			return fmt.Errorf("%s: region missing from the catalog, use -locale, -nvidia-locale and -currency", code)
+		}
+
+		regions[code] = rc
+	}
+
+	discovered, err := discover.Catalog(ctx, regions, rest.NewClient(config.APIConfig{APIURL: *apiURL}, client))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(discovered, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if *output != "" {
+		return ioutil.WriteFile(*output, data, 0644)
+	}
+
+	_, err = os.Stdout.Write(data)
+	return err
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/history"
+)
+
+// runHistory Lists the stock windows recorded in a history file.
+func runHistory(args []string) error {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	selected := addQueryFlags(flags)
+	flags.Parse(args)
+
+	polls, err := selected.polls()
+	if err != nil {
+		return err
+	}
+
+	return printWindows(os.Stdout, history.Windows(polls))
+}
+
+// queryFlags represents the flags selecting a history file and the polls to read from it.
+type queryFlags struct {
+	path   *string
+	region *string
+	model  *string
+	since  *string
+	until  *string
+}
+
+// addQueryFlags Registers the flags shared by the commands reading a history file.
+func addQueryFlags(flags *flag.FlagSet) queryFlags {
+	return queryFlags{
+		path:   flags.String("history", "history.db", "Path of the history file written with -history."),
+		region: flags.String("region", "", "Comma separated 3 Letter region codes to list E.X. USA or DEU,AUT, defaults to every region."),
+		model:  flags.String("model", "", "Comma separated GPU Model numbers to list E.X. 3080 or 3080,3090, defaults to every model."),
+		since:  flags.String("since", "", "Only include history after a date, time or duration ago E.X. 2020-09-17, 2020-09-17T13:00:00Z or 24h."),
+		until:  flags.String("until", "", "Only include history before a date, time or duration ago E.X. 2020-09-18."),
+	}
+}
+
+// polls Reads the polls selected by the flags from the history file.
+func (f queryFlags) polls() ([]history.Poll, error) {
+	now := time.Now()
+	q := history.Query{Regions: config.SplitList(*f.region), Models: config.SplitList(*f.model)}
+
+	var err error
+	q.Since, err = parseTime(*f.since, now)
+	if err != nil {
+		return nil, fmt.Errorf("-since: %v", err)
+	}
+
+	q.Until, err = parseTime(*f.until, now)
+	if err != nil {
+		return nil, fmt.Errorf("-until: %v", err)
+	}
+
+	store, err := history.OpenReadOnly(*f.path)
+	if errors.Is(err, history.ErrLocked) {
+		return nil, fmt.Errorf("%v, try again in a moment", err)
+	}
+	if errors.Is(err, history.ErrNotFound) {
+		return nil, fmt.Errorf("%v, record one with -history", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	return store.Polls(q)
+}
+
+// printWindows Writes stock windows as a table.
+func printWindows(w io.Writer, windows []history.Window) error {
+	if len(windows) == 0 {
+		_, err := fmt.Fprintln(w, "No stock windows recorded")
+		return err
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "REGION\tMODEL\tSKU\tIN STOCK\tOUT OF STOCK\tDURATION\tPRICE")
+
+	for _, window := range windows {
+		end := window.End.Format("2006-01-02 15:04:05")
+		if window.Open {
+			end = "still in stock"
+		}
+
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			window.Region,
+			window.Model,
+			window.SKU,
+			window.Start.Format("2006-01-02 15:04:05"),
+			end,
+			window.Duration().Round(time.Second),
+			history.FormatPrice(window.Price, window.Currency),
+		)
+	}
+
+	return table.Flush()
+}
+
+// parseTime Parses a date, date and time or duration before now, an empty value is the zero time.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if ago, err := time.ParseDuration(value); err == nil {
+		return now.Add(-ago), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		t, err := time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%s: must be a date E.X. 2020-09-17, a time E.X. 2020-09-17T13:00:00Z or a duration E.X. 24h", value)
+}
@@ -1,198 +1,236 @@
 package main
 
 import (
+	"context"
+	"expvar"
 	"flag"
 	"fmt"
 	"log"
-	"math/rand"
 	"net/http"
+	"os"
 	"os/exec"
+	"os/signal"
 	"runtime"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/ianmarmour/nvidia-clerk/internal/alert"
 	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/history"
+	"github.com/ianmarmour/nvidia-clerk/internal/monitor"
 	"github.com/ianmarmour/nvidia-clerk/internal/rest"
 	"github.com/ianmarmour/nvidia-clerk/internal/update"
 )
 
+// shutdownTimeout Maximum time to wait for pending notifications and servers once asked to stop.
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 	log.SetFlags(log.LstdFlags)
 
-	var region string
-	var model string
-	var delay int64
+	// Cancelled by the first SIGINT or SIGTERM, a second one exits straight away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(os.Args) > 1 && os.Args[1] == "discover" {
+		err := runDiscover(ctx, os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		err := runHistory(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "stats" {
+		err := runStats(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	// Parse Argument Flags
-	flag.StringVar(&region, "region", "", "3 Letter region code E.X. USA, GBR, DEU")
-	flag.StringVar(&model, "model", "", "GPU Model number E.X. 3070, 3080, 3090")
-	flag.Int64Var(&delay, "delay", 1, "Delay for refreshing in miliseconds")
-	twitter := flag.Bool("twitter", false, "Enable Twitter Posts for whenever SKU is in stock.")
-	twilio := flag.Bool("sms", false, "Enable SMS notifications for whenever SKU is in stock.")
-	discord := flag.Bool("discord", false, "Enable Discord webhook notifications for whenever SKU is in stock.")
-	telegram := flag.Bool("telegram", false, "Enable Telegram webhook notifications for whenever SKU is in stock.")
-	remote := flag.Bool("remote", false, "Enable remote notification only mode.")
-	desktop := flag.Bool("desktop", false, "Enable desktop notifications, disabled by default.")
-	autoUpdate := flag.Bool("update", true, "Disable automatic updates, enabled by default.")
+	path := flag.String("config", "", "Path to a YAML configuration file, flags and environment variables override its values.")
+	flag.String("catalog", "", "Path or URL of a JSON catalog of regions, models and SKUs replacing the built in catalog.")
+	flag.String("region", "", "Comma separated 3 Letter region codes E.X. USA, GBR, DEU or DEU,AUT,NLD")
+	flag.String("model", "", "Comma separated GPU Model numbers E.X. 3070, 3080, 3090 or 3080,3090")
+	flag.String("delay", "1s", "Delay between refreshes E.X. 750ms or 2s, plain numbers are miliseconds")
+	flag.Duration("jitter", monitor.DefaultJitter, "Most random time added to every delay to avoid looking like a bot E.X. 500ms, 0 disables it.")
+	flag.Float64("rate-limit", 0, "Maximum requests per second to each NVIDIA API host shared by every watch E.X. 2, disabled by default.")
+	flag.String("transitions", "", "Comma separated inventory transitions that trigger alerts E.X. out_of_stock->in_stock, * matches any state, defaults to *->in_stock,*->limited")
+	flag.Duration("cooldown", 0, "Suppress repeated alerts for a product for this long E.X. 10m, cleared when it goes out of stock, disabled by default.")
+	flag.Float64("max-price", 0, "Only alert when the sale price is at most this much in the regions currency E.X. 749.99, disabled by default.")
+	flag.Bool("price-changes", false, "Enable alerts whenever the sale price of a watched product drops or increases.")
+	flag.String("history", "", "Path of a file recording every poll and notification E.X. history.db, list it with nvidia-clerk history or stats, disabled by default.")
+	metrics := flag.String("metrics", "", "Address to serve expvar metrics such as the current polling interval on E.X. localhost:9090, disabled by default.")
+	flag.Bool("remote", false, "Enable remote notification only mode.")
+	flag.Bool("update", true, "Disable automatic updates, enabled by default.")
+	for _, channel := range alert.Channels() {
+		flag.Bool(channel.Name, false, channel.Usage)
+	}
 	flag.Parse()
 
-	config, configErr := config.Get(region, model, delay, *twilio, *discord, *twitter, *telegram, *desktop, false, *autoUpdate)
+	config, configErr := getConfig(*path)
 	if configErr != nil {
 		log.Fatal(configErr)
 	}
 	client := &http.Client{Timeout: 10 * time.Second}
+	limiter := rest.NewLimiter(config.RateLimit)
+	if peak := monitor.PeakRate(config.Watches, config.Delay); config.RateLimit > 0 && peak > config.RateLimit {
+		log.Println(fmt.Sprintf("Polling at the fastest delays makes up to %.2f requests per second, polls will queue behind the rate limit of %.2f", peak, config.RateLimit))
+	}
+	api := rest.NewClient(config.APIConfig, client)
+	api.Limiter = limiter
 
-	var (
-		mu    sync.Mutex
-		token rest.SessionToken
-	)
-	var wg sync.WaitGroup
-
-	wg.Add(3)
-	go update.FetchApply(config.SystemConfig.UpdateURL, &wg)
-	go getToken(client, delay, &token, &mu, &wg)
-	go getGPU(client, config, model, *remote, delay, &wg)
-
-	wg.Wait()
-}
+	notifiers, err := alert.Enabled(*config, client)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-func getToken(client *http.Client, delay int64, token *rest.SessionToken, mu *sync.Mutex, wg *sync.WaitGroup) error {
-	defer wg.Done()
+	filter, err := monitor.ParseFilter(config.Transitions)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	for {
-		newToken, err := rest.GetSessionToken(client)
+	var store *history.Store
+	if config.History != "" {
+		store, err = history.Open(config.History)
 		if err != nil {
-			log.Println("Error getting session token from NVIDIA retrying...")
+			log.Fatal(err)
 		}
+		defer store.Close()
+	}
 
-		if token == nil {
-			mu.Lock()
-			token = newToken
-			mu.Unlock()
-		} else {
-			if token.Value != newToken.Value {
-				mu.Lock()
-				token = newToken
-				mu.Unlock()
-			}
-		}
+	sessions := rest.NewSessionManager(api)
 
-		sleep(delay)
-	}
-}
+	var wg sync.WaitGroup
 
-func getGPU(client *http.Client, config *config.Config, model string, remote bool, delay int64, wg *sync.WaitGroup) error {
-	defer wg.Done()
+	if *metrics != "" {
+		wg.Add(1)
+		go serveMetrics(ctx, *metrics, &wg)
+	}
 
-	for {
-		sleep(delay)
+	if config.SystemConfig != nil {
+		wg.Add(1)
+		go update.FetchApply(ctx, config.SystemConfig.UpdateURL, &wg)
+	}
 
-		info, err := rest.GetSkuInfo(*config.SKU, config.Locale, config.Currency, client)
-		if err != nil {
-			continue
-		}
+	wg.Add(1 + len(config.Watches))
+	go sessions.Run(ctx, &wg)
 
-		// HACK: Resolves https://github.com/ianmarmour/nvidia-clerk/issues/85
-		if len(info.Products.Product) < 1 {
-			log.Printf("Error attempting to get product information retrying...\n")
-			continue
+	for _, watch := range config.Watches {
+		alerter := &monitor.Alerter{
+			Name:         watch.String(),
+			Filter:       filter,
+			Cooldown:     config.Cooldown,
+			MaxPrice:     watch.MaxPrice,
+			PriceChanges: config.PriceChanges,
 		}
-
-		log.Println(fmt.Sprintf("Product ID: %v", info.Products.Product[0].ID))
-		log.Println("Product Name: " + info.Products.Product[0].Name)
-		log.Println("Product Locale: " + config.Locale)
-		log.Println("Product Status: " + info.Products.Product[0].InventoryStatus.Status + "\n")
-
-		if info.Products.Product[0].InventoryStatus.Status == "PRODUCT_INVENTORY_IN_STOCK" {
-			var cartURL string
-
-			switch model {
-			case "2060":
-				cartURL = fmt.Sprintf("https://www.nvidia.com/%s/geforce/graphics-cards/rtx-%s-super/", config.NvidiaLocale, model)
-			case "2070":
-				cartURL = fmt.Sprintf("https://www.nvidia.com/%s/geforce/graphics-cards/rtx-%s-super/", config.NvidiaLocale, model)
-			case "2080":
-				cartURL = fmt.Sprintf("https://www.nvidia.com/%s/geforce/graphics-cards/rtx-%s-super/", config.NvidiaLocale, model)
-			case "2080TI":
-				cartURL = fmt.Sprintf("https://www.nvidia.com/%s/geforce/graphics-cards/rtx-%s-ti/", config.NvidiaLocale, model)
-			case "3080":
-				cartURL = fmt.Sprintf("https://www.nvidia.com/%s/geforce/graphics-cards/30-series/rtx-%s/", config.NvidiaLocale, model)
-			case "3090":
-				cartURL = fmt.Sprintf("https://www.nvidia.com/%s/geforce/graphics-cards/30-series/rtx-%s/", config.NvidiaLocale, model)
-			default:
-				cartURL = "https://www.nvidia.com/"
-			}
-
-			err = notify(info.Products.Product[0].Name, fmt.Sprintf(cartURL, model), remote, config, client)
-			if err != nil {
-				log.Println("Error attempting to send notification retrying...")
-				continue
-			}
-
-			if remote != true {
-				err = openbrowser(cartURL)
-				if err != nil {
-					log.Fatal("Error attempting to open browser.", err)
-				}
-			}
-
-			break
+		poller := &monitor.Poller{
+			Client:    api,
+			Watch:     watch,
+			Alerter:   alerter,
+			Notifiers: notifiers,
+			Sessions:  sessions,
+			History:   store,
+			Remote:    config.Remote,
+			Open:      openbrowser,
 		}
+		scheduler := monitor.NewScheduler(watch.String(), config.Delay)
+		scheduler.Jitter = config.Jitter
+		scheduler.Schedule = watch.Schedule
+		go getGPU(ctx, poller, scheduler, &wg)
 	}
 
-	return nil
+	<-ctx.Done()
+	stop()
+	log.Println(fmt.Sprintf("Shutting down, waiting up to %s for pending notifications...", shutdownTimeout))
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("Shutdown complete")
+	case <-time.After(shutdownTimeout):
+		log.Println("Timed out waiting for shutdown exiting...")
+		os.Exit(1)
+	}
 }
 
-func notify(id string, url string, remote bool, config *config.Config, client *http.Client) error {
-	if remote != true {
-		url = "Checkout avaliable on system running this program"
-	}
+// getConfig Generates Configuration from an optional configuration file, explicitly set flags take precedence over the file.
+func getConfig(path string) (*config.Config, error) {
+	file := &config.File{}
+	visit := flag.VisitAll
 
-	if config.TwilioConfig != nil {
-		err := alert.SendText(id, url, *config.TwilioConfig, client)
+	if path != "" {
+		f, err := config.ReadFile(path)
 		if err != nil {
-			log.Println("Error sending SMS notification, retrying...")
-			return err
+			return nil, err
 		}
+		file = f
+		visit = flag.Visit
 	}
 
-	if config.TwitterConfig != nil {
-		err := alert.SendTweet(id, url, *config.TwitterConfig)
-		if err != nil {
-			log.Println("Error sending Twitter notification, retrying...")
-			return err
+	var err error
+	visit(func(f *flag.Flag) {
+		if setErr := file.Set(f.Name, f.Value.String()); setErr != nil && err == nil {
+			err = fmt.Errorf("-%s: %v", f.Name, setErr)
 		}
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if config.DiscordConfig != nil {
-		message := alert.DiscordProductMessage{}
-		message.Set(url, "IN_STOCK")
-
-		err := alert.SendDiscordMessage(&message, *config.DiscordConfig, client)
+	if file.Catalog != "" {
+		catalog, err := config.LoadCatalog(file.Catalog, &http.Client{Timeout: 10 * time.Second})
 		if err != nil {
-			log.Println("Error sending Discord notification, retrying...")
-			return err
+			return nil, err
 		}
+		config.SetCatalog(catalog)
 	}
 
-	if config.TelegramConfig != nil {
-		err := alert.SendTelegramMessage(id, url, *config.TelegramConfig, client)
-		if err != nil {
-			log.Println("Error sending Telegram notification, retrying...")
-			return err
-		}
-	}
+	return file.Config()
+}
 
-	if config.ToastConfig != nil {
-		err := alert.SendToast(config.ToastConfig.OS, id)
-		if err != nil {
-			log.Println("Error sending Windows Desktop notification, retrying...")
-			return err
+// getGPU Polls a watch on the schedule until ctx is done.
+func getGPU(ctx context.Context, poller *monitor.Poller, scheduler *monitor.Scheduler, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	var err error
+
+	for {
+		if rest.Sleep(ctx, scheduler.Next(err)) != nil {
+			return
 		}
+
+		err = poller.Poll(ctx, time.Now())
 	}
+}
 
-	return nil
+// serveMetrics Serves expvar metrics at /debug/vars until ctx is done.
+func serveMetrics(ctx context.Context, addr string, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	log.Println(fmt.Sprintf("Serving metrics on http://%s/debug/vars", addr))
+	err := rest.Serve(ctx, &http.Server{Addr: addr, Handler: mux})
+	if err != nil {
+		log.Println(fmt.Sprintf("Error serving metrics: %v", err))
+	}
 }
 
 func openbrowser(url string) error {
@@ -214,13 +252,3 @@ func openbrowser(url string) error {
 
 	return nil
 }
-
-func sleep(delay int64) {
-	// Force a randomized jitter of up to 5 seconds to avoid looking like a bot.
-	rand.Seed(time.Now().UnixNano())
-	n := rand.Intn(5)
-
-	ns := time.Duration(n) * time.Second
-	ds := time.Duration(delay/1000) * time.Second
-	time.Sleep(time.Duration(ns + ds))
-}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/history"
+)
+
+// statsReport represents the restock patterns of a watch as written by the stats command.
+type statsReport struct {
+	Region         string         `json:"region"`
+	Model          string         `json:"model"`
+	SKU            string         `json:"sku"`
+	First          time.Time      `json:"first_poll"`
+	Last           time.Time      `json:"last_poll"`
+	Polls          int            `json:"polls"`
+	Errors         int            `json:"errors"`
+	Drops          int            `json:"drops"`
+	DropsPerDay    float64        `json:"drops_per_day"`
+	TypicalHour    *int           `json:"typical_hour"`
+	TypicalWeekday string         `json:"typical_weekday,omitempty"`
+	Hours          [24]int        `json:"drops_by_hour"`
+	Weekdays       map[string]int `json:"drops_by_weekday"`
+	AverageWindow  float64        `json:"average_window_seconds"`
+	LongestWindow  float64        `json:"longest_window_seconds"`
+	Currency       string         `json:"currency,omitempty"`
+	MinPrice       string         `json:"min_price,omitempty"`
+	MaxPrice       string         `json:"max_price,omitempty"`
+	Prices         []priceReport  `json:"prices"`
+}
+
+// priceReport represents a sale price change as written by the stats command.
+type priceReport struct {
+	Time  time.Time `json:"time"`
+	Price string    `json:"price"`
+}
+
+// runStats Summarizes the restock patterns recorded in a history file.
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	selected := addQueryFlags(flags)
+	format := flags.String("format", "table", "Output format, one of table, json or csv.")
+	timezone := flags.String("timezone", "", "IANA time zone restock hours and weekdays are reported in E.X. America/New_York, defaults to the local time zone.")
+	flags.Parse(args)
+
+	write, ok := map[string]func(io.Writer, []statsReport) error{
+		"table": printStats,
+		"json":  writeStatsJSON,
+		"csv":   writeStatsCSV,
+	}[*format]
+	if ok == false {
+		return fmt.Errorf("-format: %s: must be one of table, json or csv", *format)
+	}
+
+	loc := time.Local
+	if *timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(*timezone)
+		if err != nil {
+			return fmt.Errorf("-timezone: %v", err)
+		}
+	}
+
+	polls, err := selected.polls()
+	if err != nil {
+		return err
+	}
+
+	reports := []statsReport{}
+	for _, stats := range history.Summarize(polls, loc) {
+		reports = append(reports, newStatsReport(stats, loc))
+	}
+
+	return write(os.Stdout, reports)
+}
+
+// newStatsReport Converts Stats for output with times in loc and formatted prices.
+func newStatsReport(stats history.Stats, loc *time.Location) statsReport {
+	report := statsReport{
+		Region:        stats.Region,
+		Model:         stats.Model,
+		SKU:           stats.SKU,
+		First:         stats.First.In(loc),
+		Last:          stats.Last.In(loc),
+		Polls:         stats.Polls,
+		Errors:        stats.Errors,
+		Drops:         stats.Drops,
+		DropsPerDay:   stats.DropsPerDay(),
+		Hours:         stats.Hours,
+		Weekdays:      map[string]int{},
+		AverageWindow: stats.AverageWindow.Seconds(),
+		LongestWindow: stats.LongestWindow.Seconds(),
+		Currency:      stats.Currency,
+		Prices:        []priceReport{},
+	}
+
+	hour, ok := stats.TypicalHour()
+	if ok {
+		report.TypicalHour = &hour
+	}
+
+	day, ok := stats.TypicalWeekday()
+	if ok {
+		report.TypicalWeekday = day.String()
+	}
+
+	for i, count := range stats.Weekdays {
+		report.Weekdays[time.Weekday(i).String()] = count
+	}
+
+	min, max := stats.PriceRange()
+	report.MinPrice = history.FormatPrice(min, stats.Currency)
+	report.MaxPrice = history.FormatPrice(max, stats.Currency)
+
+	for _, point := range stats.Prices {
+		report.Prices = append(report.Prices, priceReport{Time: point.Time.In(loc), Price: history.FormatPrice(point.Price, stats.Currency)})
+	}
+
+	return report
+}
+
+// typicalHour Formats the typical restock hour as a range E.X. 13:00-14:00, empty when nothing restocked.
+func (r statsReport) typicalHour() string {
+	if r.TypicalHour == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%02d:00-%02d:00", *r.TypicalHour, (*r.TypicalHour+1)%24)
+}
+
+// priceRange Formats the lowest and highest prices, a single price when it never changed.
+func (r statsReport) priceRange() string {
+	if r.MinPrice == r.MaxPrice {
+		return r.MinPrice
+	}
+
+	return fmt.Sprintf("%s - %s", r.MinPrice, r.MaxPrice)
+}
+
+// priceChanges Counts how many times the price changed after it was first seen.
+func (r statsReport) priceChanges() int {
+	if len(r.Prices) == 0 {
+		return 0
+	}
+
+	return len(r.Prices) - 1
+}
+
+// printStats Writes stats as a table.
+func printStats(w io.Writer, reports []statsReport) error {
+	if len(reports) == 0 {
+		_, err := fmt.Fprintln(w, "No polls recorded")
+		return err
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "REGION\tMODEL\tSKU\tPOLLS\tDROPS\tPER DAY\tTYPICAL HOUR\tTYPICAL DAY\tAVG WINDOW\tLONGEST\tPRICE\tPRICE CHANGES")
+
+	for _, r := range reports {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%d\t%.2f\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			r.Region,
+			r.Model,
+			r.SKU,
+			r.Polls,
+			r.Drops,
+			r.DropsPerDay,
+			r.typicalHour(),
+			r.TypicalWeekday,
+			seconds(r.AverageWindow),
+			seconds(r.LongestWindow),
+			r.priceRange(),
+			r.priceChanges(),
+		)
+	}
+
+	return table.Flush()
+}
+
+// writeStatsJSON Writes stats as a JSON array including the full price history.
+func writeStatsJSON(w io.Writer, reports []statsReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(reports)
+}
+
+// writeStatsCSV Writes stats as CSV with a header row, restocks by hour follow as one column per hour.
+func writeStatsCSV(w io.Writer, reports []statsReport) error {
+	out := csv.NewWriter(w)
+
+	header := []string{"region", "model", "sku", "first_poll", "last_poll", "polls", "errors", "drops", "drops_per_day", "typical_hour", "typical_weekday", "average_window_seconds", "longest_window_seconds", "currency", "min_price", "max_price", "price_changes"}
+	for hour := 0; hour < 24; hour++ {
+		header = append(header, fmt.Sprintf("drops_%02dh", hour))
+	}
+	out.Write(header)
+
+	for _, r := range reports {
+		hour := ""
+		if r.TypicalHour != nil {
+			hour = strconv.Itoa(*r.TypicalHour)
+		}
+
+		record := []string{
+			r.Region,
+			r.Model,
+			r.SKU,
+			r.First.Format(time.RFC3339),
+			r.Last.Format(time.RFC3339),
+			strconv.Itoa(r.Polls),
+			strconv.Itoa(r.Errors),
+			strconv.Itoa(r.Drops),
+			strconv.FormatFloat(r.DropsPerDay, 'f', 2, 64),
+			hour,
+			r.TypicalWeekday,
+			strconv.FormatFloat(r.AverageWindow, 'f', 0, 64),
+			strconv.FormatFloat(r.LongestWindow, 'f', 0, 64),
+			r.Currency,
+			r.MinPrice,
+			r.MaxPrice,
+			strconv.Itoa(r.priceChanges()),
+		}
+		for _, count := range r.Hours {
+			record = append(record, strconv.Itoa(count))
+		}
+		out.Write(record)
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// seconds Formats a number of seconds as a duration E.X. 1m10s.
+func seconds(s float64) string {
+	return (time.Duration(s) * time.Second).String()
+}
@@ -1,13 +1,16 @@
 module github.com/ianmarmour/nvidia-clerk
 
-go 1.14
+go 1.16
 
 require (
-	github.com/cenkalti/backoff v2.2.1+incompatible // indirect
+	github.com/cenkalti/backoff v2.2.1+incompatible
 	github.com/dghubble/go-twitter v0.0.0-20200725221434-4bc8ad7ad1b4
 	github.com/dghubble/oauth1 v0.6.0
 	github.com/gorilla/mux v1.8.0
 	github.com/ianmarmour/nvidia-clerk/third_party/toast v0.0.0-20200928234042-7bfe071b2f68
 	github.com/inconshreveable/go-update v0.0.0-20160112193335-8152e7eb6ccf
 	github.com/stretchr/testify v1.3.0
+	go.etcd.io/bbolt v1.3.6
+	golang.org/x/sys v0.10.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -1,6 +1,14 @@
 package alert
 
-import "net/http"
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+)
 
 // RoundTripFunc .
 type RoundTripFunc func(req *http.Request) *http.Response
@@ -16,3 +24,35 @@ func NewTestClient(fn RoundTripFunc) *http.Client {
 		Transport: RoundTripFunc(fn),
 	}
 }
+
+// readTestFile Writes a configuration file to a temporary directory and reads it back.
+func readTestFile(t *testing.T, data string) *config.File {
+	path := filepath.Join(t.TempDir(), "clerk.yaml")
+
+	err := ioutil.WriteFile(path, []byte(data), 0600)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	file, err := config.ReadFile(path)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return file
+}
+
+// fileErrors Gets the line and message of every problem reported for a configuration file.
+func fileErrors(t *testing.T, err error) []string {
+	errs, ok := err.(config.FileErrors)
+	if ok == false {
+		t.Fatalf("Expected FileErrors, got %#v", err)
+	}
+
+	messages := []string{}
+	for _, e := range errs {
+		messages = append(messages, fmt.Sprintf("%d: %s", e.Line, e.Message))
+	}
+
+	return messages
+}
@@ -0,0 +1,100 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+// NotifyAPI Sends a change in the status of one of NVIDIAs APIs to every notifier that supports it, returning the last error.
+func NotifyAPI(ctx context.Context, notifiers []Notifier, event APIEvent) error {
+	var last error
+
+	for _, notifier := range notifiers {
+		apiNotifier, ok := notifier.(APINotifier)
+		if ok == false {
+			continue
+		}
+
+		err := apiNotifier.NotifyAPI(ctx, event)
+		if err != nil {
+			log.Println(fmt.Sprintf("Error sending %s notification for %s: %v", notifier.Name(), event.Name, err))
+			last = err
+		}
+	}
+
+	return last
+}
+
+// apiWatch tracks the status of one of NVIDIAs APIs, notifying only when it changes.
+type apiWatch struct {
+	name      string
+	notifiers []Notifier
+	previous  string
+}
+
+// check Records the result of calling the API, any error means it is offline.
+func (w *apiWatch) check(ctx context.Context, err error) {
+	status := "online"
+	if err != nil {
+		status = "offline"
+	}
+
+	if status == w.previous {
+		return
+	}
+	w.previous = status
+
+	log.Println(fmt.Sprintf("Sending notifications for %s %s", w.name, status))
+	NotifyAPI(ctx, w.notifiers, APIEvent{Name: w.name, Status: status})
+}
+
+// StartAPINotifications Runs a loop and notifies every enabled channel that supports it when there is a status change until ctx is done.
+func StartAPINotifications(ctx context.Context, region string, api string, config config.Config, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	store := rest.NewClient(config.APIConfig, client)
+	sessions := rest.NewSessionManager(store)
+	watch := config.Watches[0]
+
+	notifiers, err := Enabled(config, client)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	w := apiWatch{name: "Store Session", notifiers: notifiers}
+	if api == "checkout" {
+		w.name = fmt.Sprintf("%s Store Product Checkout", region)
+	}
+
+	check := make(chan bool, 1)
+
+	go func() {
+		time.Sleep(61 * time.Second)
+		check <- true
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-check:
+			switch api {
+			case "session":
+				_, sessErr := store.GetSessionToken(ctx)
+				w.check(ctx, sessErr)
+			case "checkout":
+				_, chkErr := sessions.AddToCheckout(ctx, watch.SKU, watch.NvidiaLocale)
+				w.check(ctx, chkErr)
+			}
+		}
+	}
+}
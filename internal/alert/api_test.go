@@ -0,0 +1,70 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIWatch(t *testing.T) {
+	messages := map[string][]string{}
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		body, _ := ioutil.ReadAll(req.Body)
+
+		payload := map[string]interface{}{}
+		json.Unmarshal(body, &payload)
+
+		message, ok := payload["content"]
+		if ok == false {
+			message = payload["message"]
+		}
+		messages[req.URL.String()] = append(messages[req.URL.String()], message.(string))
+
+		return webhookResponse(200)
+	})
+
+	webhook := testWebhookConfig("https://example.com/hook")
+	cfg := config.Config{Notifiers: map[string]interface{}{
+		"discord":  &DiscordConfig{WebhookURL: "http://testurl/webhook/"},
+		"webhook":  &webhook,
+		"telegram": &TelegramConfig{APIKey: "1", ChatID: "1"},
+	}}
+
+	notifiers, err := Enabled(cfg, client)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	w := apiWatch{name: "Store Session", notifiers: notifiers}
+	w.check(context.Background(), nil)
+	w.check(context.Background(), nil)
+	w.check(context.Background(), errors.New("session unavailable"))
+
+	expected := []string{"NVIDIA API Store Session is now online", "NVIDIA API Store Session is now offline"}
+	assert.Equal(t, map[string][]string{
+		"http://testurl/webhook/":  expected,
+		"https://example.com/hook": expected,
+	}, messages, "only changes are sent and only to channels supporting API events")
+}
+
+func TestNotifyAPIError(t *testing.T) {
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		return webhookResponse(503)
+	})
+
+	cfg := config.Config{Notifiers: map[string]interface{}{"discord": &DiscordConfig{WebhookURL: "http://testurl/webhook/"}}}
+
+	notifiers, err := Enabled(cfg, client)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = NotifyAPI(context.Background(), notifiers, APIEvent{Name: "Store Session", Status: "offline"})
+	assert.NotNil(t, err)
+}
@@ -1,11 +1,12 @@
 package alert
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,6 +14,75 @@ import (
 	"github.com/ianmarmour/nvidia-clerk/internal/rest"
 )
 
+func init() {
+	Register(Channel{
+		Name:     "discord",
+		Usage:    "Enable Discord webhook notifications for whenever SKU is in stock.",
+		New:      newDiscordNotifier,
+		Settings: config.Section{New: func() interface{} { return &DiscordConfig{} }, Resolve: resolveDiscord},
+	})
+}
+
+// DiscordConfig represents the Discord webhook messages are sent to.
+type DiscordConfig struct {
+	WebhookURL string `yaml:"webhook_url"`
+}
+
+// resolveDiscord Reads DISCORD_WEBHOOK_URL over the value of the configuration file.
+func resolveDiscord(settings interface{}, r *config.Resolver) {
+	c := settings.(*DiscordConfig)
+	r.Required("webhook_url", "DISCORD_WEBHOOK_URL", &c.WebhookURL)
+}
+
+// discordNotifier delivers stock events and API status changes as Discord webhook messages.
+type discordNotifier struct {
+	config    DiscordConfig
+	templates Templates
+	client    *http.Client
+}
+
+func newDiscordNotifier(cfg config.Config, client *http.Client) (Notifier, error) {
+	settings, ok := cfg.Notifiers["discord"].(*DiscordConfig)
+	if ok == false {
+		return nil, nil
+	}
+
+	templates, err := NewTemplates(cfg, "discord")
+	if err != nil {
+		return nil, err
+	}
+
+	return &discordNotifier{*settings, templates, client}, nil
+}
+
+// Name returns the channel name of the notifier.
+func (n *discordNotifier) Name() string {
+	return "discord"
+}
+
+// Notify sends a Discord message for a stock event.
+func (n *discordNotifier) Notify(ctx context.Context, event StockEvent) error {
+	content, err := n.templates.Render(event.Kind(), event)
+	if err != nil {
+		return err
+	}
+
+	message := DiscordProductMessage{}
+	message.SetEvent(event, content)
+
+	return SendDiscordMessage(ctx, &message, n.config, n.client)
+}
+
+// NotifyAPI sends a Discord message for a change in the status of one of NVIDIAs APIs.
+func (n *discordNotifier) NotifyAPI(ctx context.Context, event APIEvent) error {
+	content, err := n.templates.Render(event.Kind(), event)
+	if err != nil {
+		return err
+	}
+
+	return SendDiscordMessage(ctx, &DiscordAPIMessage{body: content}, n.config, n.client)
+}
+
 // DiscordMessage represents a discord message
 type DiscordMessage interface {
 	Get() string
@@ -48,7 +118,8 @@ func (d *DiscordAPIMessage) JSON() ([]byte, error) {
 
 // DiscordProductMessage represents a discord message relating to product avaliablity.
 type DiscordProductMessage struct {
-	body string
+	body  string
+	event *StockEvent
 }
 
 // Get returns the current DiscordProductMessage
@@ -56,14 +127,29 @@ func (d *DiscordProductMessage) Get() string {
 	return d.body
 }
 
-// Set takes in a product URL and returns the JSON body for a Discord POST request
+// Set takes in a product URL and status and returns the JSON body for a Discord POST request
 func (d *DiscordProductMessage) Set(url string, status string) {
-	d.body = url
+	if status == "" {
+		d.body = url
+		return
+	}
+
+	d.body = fmt.Sprintf("%s: %s", StatusText(status), url)
+}
+
+// SetEvent takes in a StockEvent and its rendered message and adds an embed with the products price, region and image
+func (d *DiscordProductMessage) SetEvent(event StockEvent, content string) {
+	d.event = &event
+	d.body = content
 }
 
 // JSON returns the JSON encoded bytes of a DiscordProductMessage
 func (d *DiscordProductMessage) JSON() ([]byte, error) {
-	body := map[string]string{"content": d.Get()}
+	body := map[string]interface{}{"content": d.Get()}
+	if d.event != nil {
+		body["embeds"] = []interface{}{discordEmbed(*d.event)}
+	}
+
 	json, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
@@ -72,108 +158,67 @@ func (d *DiscordProductMessage) JSON() ([]byte, error) {
 	return json, nil
 }
 
-//SendDiscordMessage Sends a notification message to a Discord Webhook.
-func SendDiscordMessage(message DiscordMessage, config config.DiscordConfig, client *http.Client) error {
-	json, err := message.JSON()
-	if err != nil {
-		return err
+// discordEmbed Generates a Discord embed describing a StockEvent.
+func discordEmbed(event StockEvent) map[string]interface{} {
+	fields := []map[string]interface{}{
+		{"name": "Status", "value": StatusText(event.Status), "inline": true},
 	}
 
-	req, err := http.NewRequest("POST", config.WebhookURL, bytes.NewBuffer(json))
-	if err != nil {
-		return err
+	if event.Price != "" {
+		fields = append(fields, map[string]interface{}{"name": "Price", "value": event.Price, "inline": true})
 	}
-	req.Header.Add("Content-Type", "application/json")
 
-	r, err := client.Do(req)
-	if err != nil {
-		return err
+	if event.PreviousPrice != "" {
+		fields = append(fields, map[string]interface{}{"name": "Previous Price", "value": event.PreviousPrice, "inline": true})
 	}
-	if r.StatusCode > 400 {
-		return err
-	}
-
-	defer r.Body.Close()
 
-	return nil
-}
+	if event.Region != "" {
+		fields = append(fields, map[string]interface{}{"name": "Region", "value": event.Region, "inline": true})
+	}
 
-// StartDiscordAPINotifications Runs a loop and notifies discord when there is a status change.
-func StartDiscordAPINotifications(region string, api string, config config.Config, wg *sync.WaitGroup) {
-	defer wg.Done()
+	embed := map[string]interface{}{
+		"title":  event.Title(),
+		"fields": fields,
+	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	previousStatus := ""
+	if strings.HasPrefix(event.CartURL, "http") {
+		embed["url"] = event.CartURL
+	}
 
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
+	if event.Thumbnail != "" {
+		embed["thumbnail"] = map[string]string{"url": event.Thumbnail}
+	}
 
-	check := make(chan bool)
+	if event.Timestamp.IsZero() == false {
+		embed["timestamp"] = event.Timestamp.Format(time.RFC3339)
+	}
 
-	go func() {
-		time.Sleep(61 * time.Second)
-		check <- true
-	}()
+	return embed
+}
 
-	for {
-		select {
-		case <-check:
-			switch api {
-			case "session":
-				_, sessErr := rest.GetSessionToken(client)
-				if sessErr != nil {
-					if previousStatus != "offline" {
-						message := DiscordAPIMessage{}
-						message.Set("Store Session", "offline")
-						SendDiscordMessage(&message, *config.DiscordConfig, client)
-						previousStatus = "offline"
-						log.Println(fmt.Sprintf("Sending Discord Notification for %s", config.Locale))
-					}
-				} else {
-					if previousStatus != "online" {
-						message := DiscordAPIMessage{}
-						message.Set("Store Session", "online")
-						SendDiscordMessage(&message, *config.DiscordConfig, client)
-						previousStatus = "online"
-						log.Println(fmt.Sprintf("Sending Discord Notification for %s", config.Locale))
-					}
-				}
-			case "checkout":
-				token, _ := rest.GetSessionToken(client)
-				_, chkErr := rest.AddToCheckout(*config.SKU, token.Value, config.NvidiaLocale, client)
-				if chkErr != nil {
-					if previousStatus != "offline" {
-						message := DiscordAPIMessage{}
-						message.Set(fmt.Sprintf("%s Store Product Checkout", region), "offline")
-						SendDiscordMessage(&message, *config.DiscordConfig, client)
-						log.Println(fmt.Sprintf("Sending Discord Notification for %s", config.Locale))
-						previousStatus = "offline"
-					}
-				} else {
-					if previousStatus != "online" {
-						message := DiscordAPIMessage{}
-						message.Set(fmt.Sprintf("%s Store Product Checkout", region), "online")
-						SendDiscordMessage(&message, *config.DiscordConfig, client)
-						log.Println(fmt.Sprintf("Sending Discord Notification for %s", config.Locale))
-						previousStatus = "online"
-					}
-				}
-			}
-		}
+//SendDiscordMessage Sends a notification message to a Discord Webhook.
+func SendDiscordMessage(ctx context.Context, message DiscordMessage, config DiscordConfig, client *http.Client) error {
+	json, err := message.JSON()
+	if err != nil {
+		return err
 	}
+
+	return postJSON(ctx, client, config.WebhookURL, json, nil)
 }
 
-// StartDiscordProductNotifications Runs a loop and notifies discord when there is a status change.
-func StartDiscordProductNotifications(model string, config config.Config, wg *sync.WaitGroup) {
+// StartDiscordProductNotifications Runs a loop and notifies discord when there is a status change until ctx is done.
+func StartDiscordProductNotifications(ctx context.Context, model string, config config.Config, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	client := &http.Client{Timeout: 10 * time.Second}
+	store := rest.NewClient(config.APIConfig, client)
+	watch := config.Watches[0]
 	previousStatus := ""
 
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
-	check := make(chan bool)
+	check := make(chan bool, 1)
 
 	go func() {
 		time.Sleep(61 * time.Second)
@@ -182,12 +227,14 @@ func StartDiscordProductNotifications(model string, config config.Config, wg *sy
 
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case <-check:
-			_, sessErr := rest.GetSessionToken(client)
+			_, sessErr := store.GetSessionToken(ctx)
 			if sessErr != nil {
-				info, err := rest.GetSkuInfo(*config.SKU, config.Locale, config.Currency, client)
+				info, err := store.GetSkuInfo(ctx, watch.SKU, watch.Locale, watch.Currency)
 				if err != nil {
-					log.Println(fmt.Sprintf("Error attempting to get product information for %s in %s", *config.SKU, config.Locale))
+					log.Println(fmt.Sprintf("Error attempting to get product information for %s in %s", watch.SKU, watch.Locale))
 					return
 				}
 
@@ -195,9 +242,9 @@ func StartDiscordProductNotifications(model string, config config.Config, wg *sy
 					if previousStatus != "instock" {
 						message := DiscordProductMessage{}
 						message.Set(fmt.Sprintf("%s in stock now", model), "")
-						SendDiscordMessage(&message, *config.DiscordConfig, client)
+						SendDiscordMessage(ctx, &message, *config.Notifiers["discord"].(*DiscordConfig), client)
 						previousStatus = "instock"
-						log.Println(fmt.Sprintf("Sending Discord Notification for %s", config.Locale))
+						log.Println(fmt.Sprintf("Sending Discord Notification for %s", watch.Locale))
 					}
 				}
 			}
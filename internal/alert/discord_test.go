@@ -2,11 +2,13 @@ package alert
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"testing"
 
-	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
 )
 
 func TestSendDiscordMessage(t *testing.T) {
@@ -26,14 +28,14 @@ func TestSendDiscordMessage(t *testing.T) {
 		}
 	})
 
-	cfg := config.DiscordConfig{
+	cfg := DiscordConfig{
 		WebhookURL: "http://testurl/webhook/",
 	}
 
 	productMsg := DiscordProductMessage{}
 	productMsg.Set("test", "test")
 
-	err := SendDiscordMessage(&productMsg, cfg, client)
+	err := SendDiscordMessage(context.Background(), &productMsg, cfg, client)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -41,8 +43,14 @@ func TestSendDiscordMessage(t *testing.T) {
 	apiMsg := DiscordAPIMessage{}
 	apiMsg.Set("test", "test")
 
-	err = SendDiscordMessage(&apiMsg, cfg, client)
+	err = SendDiscordMessage(context.Background(), &apiMsg, cfg, client)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
+
+	cfg.WebhookURL = "http://testurl/invalid/"
+	err = SendDiscordMessage(context.Background(), &productMsg, cfg, client)
+	if errors.Is(err, rest.ErrServerError) == false {
+		t.Errorf("Expected server error, got %v", err)
+	}
 }
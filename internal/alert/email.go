@@ -0,0 +1,435 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+)
+
+func init() {
+	Register(Channel{
+		Name:     "email",
+		Usage:    "Enable email notifications through an SMTP server for whenever SKU is in stock.",
+		New:      newEmailNotifier,
+		Settings: config.Section{New: func() interface{} { return &EmailConfig{} }, Resolve: resolveEmail},
+	})
+}
+
+// Ways of securing the connection to an SMTP server.
+const (
+	EmailTLSStartTLS = "starttls"
+	EmailTLSImplicit = "implicit"
+	EmailTLSNone     = "none"
+)
+
+// EmailConfig represents an SMTP server and the addresses emails are sent from and to.
+type EmailConfig struct {
+	Host     string   `yaml:"host"`
+	Port     string   `yaml:"port"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+
+	// TLS one of EmailTLSStartTLS, EmailTLSImplicit or EmailTLSNone, the port defaults to 587, 465 or 25 to match.
+	TLS string `yaml:"tls"`
+}
+
+// resolveEmail Reads the SMTP_ environment variables over the values of the configuration file, filling in the port for its TLS mode.
+func resolveEmail(settings interface{}, r *config.Resolver) {
+	c := settings.(*EmailConfig)
+
+	r.Required("host", "SMTP_HOST", &c.Host)
+	r.Required("from", "SMTP_FROM", &c.From)
+	r.Optional("SMTP_PORT", &c.Port)
+	r.Optional("SMTP_USERNAME", &c.Username)
+	r.Optional("SMTP_PASSWORD", &c.Password)
+	r.Optional("SMTP_TLS", &c.TLS)
+	r.List("SMTP_TO", &c.To)
+
+	if c.From != "" {
+		_, err := mail.ParseAddress(c.From)
+		if err != nil {
+			r.Report(fmt.Sprintf("%s: from must be an email address: %v", c.From, err), "from")
+		}
+	}
+
+	if len(c.To) == 0 {
+		r.Report("to is required, set it in the configuration file or with SMTP_TO", "to")
+	}
+
+	for i, to := range c.To {
+		_, err := mail.ParseAddress(to)
+		if err != nil {
+			r.Report(fmt.Sprintf("%s: to must be email addresses: %v", to, err), "to", i)
+		}
+	}
+
+	ports := map[string]string{EmailTLSStartTLS: "587", EmailTLSImplicit: "465", EmailTLSNone: "25"}
+
+	if c.TLS == "" {
+		c.TLS = EmailTLSStartTLS
+	}
+
+	port, ok := ports[c.TLS]
+	if ok == false {
+		r.Report(fmt.Sprintf("%s: tls must be one of %s, %s or %s", c.TLS, EmailTLSStartTLS, EmailTLSImplicit, EmailTLSNone), "tls")
+	}
+
+	if c.Port == "" {
+		c.Port = port
+	} else if n, err := strconv.Atoi(c.Port); err != nil || n < 1 || n > 65535 {
+		r.Report(fmt.Sprintf("%s: port must be a number between 1 and 65535", c.Port), "port")
+	}
+}
+
+// emailHTML Lays out the HTML body of every email, the plain text body carries the same details.
+var emailHTML = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, Helvetica, sans-serif; color: #1a1a1a;">
+<h2 style="color: #76b900;">{{.Title}}</h2>
+<p>{{.Summary}}</p>
+{{- if .Image}}
+<p><img src="{{.Image}}" alt="{{.Title}}" width="240"></p>
+{{- end}}
+{{- if .Details}}
+<table cellpadding="4">
+{{- range .Details}}
+<tr><th align="left">{{.Name}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Link}}
+<p><a href="{{.Link}}" style="display: inline-block; padding: 10px 16px; background: #76b900; color: #ffffff; text-decoration: none;">{{.Label}}</a></p>
+{{- end}}
+</body>
+</html>
+`))
+
+// emailDetail represents a labelled value listed in an email.
+type emailDetail struct {
+	Name  string
+	Value string
+}
+
+// emailBody represents the content of an email shared by its plain text and HTML bodies.
+type emailBody struct {
+	Title   string
+	Summary string
+	Image   string
+	Details []emailDetail
+	Link    string
+	Label   string
+}
+
+// emailNotifier delivers stock events and API status changes as emails through an SMTP server.
+type emailNotifier struct {
+	config    EmailConfig
+	templates Templates
+
+	// tls overrides how the server certificate is checked, nil verifies it against the system roots.
+	tls *tls.Config
+}
+
+func newEmailNotifier(cfg config.Config, client *http.Client) (Notifier, error) {
+	settings, ok := cfg.Notifiers["email"].(*EmailConfig)
+	if ok == false {
+		return nil, nil
+	}
+
+	templates, err := NewTemplates(cfg, "email")
+	if err != nil {
+		return nil, err
+	}
+
+	return &emailNotifier{config: *settings, templates: templates}, nil
+}
+
+// Name returns the channel name of the notifier.
+func (n *emailNotifier) Name() string {
+	return "email"
+}
+
+// Notify sends an email for a stock event.
+func (n *emailNotifier) Notify(ctx context.Context, event StockEvent) error {
+	subject, err := n.templates.Render(event.Kind(), event)
+	if err != nil {
+		return err
+	}
+
+	message, err := NewStockEmail(event, subject)
+	if err != nil {
+		return err
+	}
+
+	return SendEmail(ctx, message, n.config, n.tls)
+}
+
+// NotifyAPI sends an email for a change in the status of one of NVIDIAs APIs.
+func (n *emailNotifier) NotifyAPI(ctx context.Context, event APIEvent) error {
+	subject, err := n.templates.Render(event.Kind(), event)
+	if err != nil {
+		return err
+	}
+
+	message, err := NewAPIEmail(event, subject)
+	if err != nil {
+		return err
+	}
+
+	return SendEmail(ctx, message, n.config, n.tls)
+}
+
+// EmailMessage represents an email with equivalent plain text and HTML bodies.
+type EmailMessage struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// NewStockEmail Generates an EmailMessage describing a StockEvent with its rendered message as the subject.
+func NewStockEmail(event StockEvent, subject string) (EmailMessage, error) {
+	body := emailBody{Title: event.Title(), Summary: subject, Image: event.Thumbnail}
+
+	body.Details = append(body.Details, emailDetail{"Status", StatusText(event.Status)})
+
+	if event.Price != "" {
+		body.Details = append(body.Details, emailDetail{"Price", event.Price})
+	}
+
+	if event.PreviousPrice != "" {
+		body.Details = append(body.Details, emailDetail{"Previous Price", event.PreviousPrice})
+	}
+
+	if event.Region != "" {
+		body.Details = append(body.Details, emailDetail{"Region", event.Region})
+	}
+
+	if event.Timestamp.IsZero() == false {
+		body.Details = append(body.Details, emailDetail{"Time", event.Timestamp.Format("2006-01-02 15:04:05 MST")})
+	}
+
+	if strings.HasPrefix(event.CartURL, "http") {
+		body.Link = event.CartURL
+		body.Label = "View product"
+		if event.Kind() == KindInStock {
+			body.Label = "Go to cart"
+		}
+	}
+
+	return newEmailMessage(subject, body)
+}
+
+// NewAPIEmail Generates an EmailMessage describing an APIEvent with its rendered message as the subject.
+func NewAPIEmail(event APIEvent, subject string) (EmailMessage, error) {
+	body := emailBody{
+		Title:   fmt.Sprintf("NVIDIA API %s", event.Name),
+		Summary: subject,
+		Details: []emailDetail{{"Status", event.Status}},
+	}
+
+	return newEmailMessage(subject, body)
+}
+
+func newEmailMessage(subject string, body emailBody) (EmailMessage, error) {
+	// Templates may span lines but a subject header can't.
+	subject = strings.Join(strings.Fields(subject), " ")
+
+	text := []string{body.Summary, ""}
+	for _, detail := range body.Details {
+		text = append(text, fmt.Sprintf("%s: %s", detail.Name, detail.Value))
+	}
+	if body.Link != "" {
+		text = append(text, fmt.Sprintf("%s: %s", body.Label, body.Link))
+	}
+
+	var html bytes.Buffer
+	err := emailHTML.Execute(&html, body)
+	if err != nil {
+		return EmailMessage{}, err
+	}
+
+	return EmailMessage{Subject: subject, Text: strings.Join(text, "\n") + "\n", HTML: html.String()}, nil
+}
+
+// Bytes Encodes the message as a multipart/alternative MIME message with quoted-printable plain text and HTML parts.
+func (m EmailMessage) Bytes(from string, to []string, date time.Time) ([]byte, error) {
+	var parts bytes.Buffer
+	writer := multipart.NewWriter(&parts)
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qp := quotedprintable.NewWriter(w)
+		_, err = qp.Write([]byte(part.body))
+		if err != nil {
+			return nil, err
+		}
+
+		err = qp.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+	b.Write(parts.Bytes())
+
+	return b.Bytes(), nil
+}
+
+// SendEmail Sends an email through the SMTP server in cfg, tlsConfig overrides how its certificate is verified.
+//
+// With EmailTLSStartTLS the server must support STARTTLS, it is never skipped silently.
+func SendEmail(ctx context.Context, message EmailMessage, cfg EmailConfig, tlsConfig *tls.Config) error {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("from: %v", err)
+	}
+
+	to := []string{}
+	for _, recipient := range cfg.To {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("to: %v", err)
+		}
+		to = append(to, address.Address)
+	}
+
+	data, err := message.Bytes(from.String(), cfg.To, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: cfg.Host}
+	}
+
+	addr := net.JoinHostPort(cfg.Host, cfg.Port)
+	dialer := &net.Dialer{}
+
+	var conn net.Conn
+	if cfg.TLS == EmailTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Closing the connection interrupts the conversation with the server once ctx is done.
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	err = sendSMTP(conn, cfg, tlsConfig, from.Address, to, data)
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// sendSMTP Delivers an encoded message over a connection to an SMTP server.
+func sendSMTP(conn net.Conn, cfg EmailConfig, tlsConfig *tls.Config, from string, to []string, data []byte) error {
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if cfg.TLS == EmailTLSStartTLS {
+		ok, _ := c.Extension("STARTTLS")
+		if ok == false {
+			return fmt.Errorf("%s doesn't support STARTTLS, set tls to none to send unencrypted", cfg.Host)
+		}
+
+		err = c.StartTLS(tlsConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Username != "" {
+		err = c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.Mail(from)
+	if err != nil {
+		return err
+	}
+
+	for _, recipient := range to {
+		err = c.Rcpt(recipient)
+		if err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,426 @@
+package alert
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io/ioutil"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// smtpMessage represents an email received by an smtpStandIn.
+type smtpMessage struct {
+	From string
+	To   []string
+	Auth string
+	TLS  bool
+	Data string
+}
+
+// smtpStandIn represents a local SMTP server accepting every message, offering STARTTLS when it has a certificate.
+type smtpStandIn struct {
+	listener net.Listener
+	tls      *tls.Config
+	implicit bool
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func newSMTPStandIn(t *testing.T, tlsConfig *tls.Config, implicit bool) *smtpStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if implicit {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	s := &smtpStandIn{listener: listener, tls: tlsConfig, implicit: implicit}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+
+	return s
+}
+
+// Port Gets the port the stand-in is listening on.
+func (s *smtpStandIn) Port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+// Messages Gets every message received so far.
+func (s *smtpStandIn) Messages() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]smtpMessage{}, s.messages...)
+}
+
+func (s *smtpStandIn) Close() {
+	s.listener.Close()
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+
+	text := textproto.NewConn(conn)
+	message := smtpMessage{TLS: s.implicit}
+
+	text.PrintfLine("220 127.0.0.1 ESMTP stand-in")
+
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch command {
+		case "EHLO", "HELO":
+			text.PrintfLine("250-127.0.0.1")
+			if s.tls != nil && message.TLS == false {
+				text.PrintfLine("250-STARTTLS")
+			}
+			text.PrintfLine("250 AUTH PLAIN")
+		case "STARTTLS":
+			text.PrintfLine("220 Ready to start TLS")
+
+			secure := tls.Server(conn, s.tls)
+			if secure.Handshake() != nil {
+				return
+			}
+
+			conn = secure
+			text = textproto.NewConn(conn)
+			message.TLS = true
+		case "AUTH":
+			fields := strings.Fields(line)
+			credentials, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+			message.Auth = string(credentials)
+			text.PrintfLine("235 Authenticated")
+		case "MAIL":
+			message.From = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			text.PrintfLine("250 OK")
+		case "RCPT":
+			message.To = append(message.To, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			message.Data = string(data)
+
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+
+			text.PrintfLine("250 OK")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("250 OK")
+		}
+	}
+}
+
+// testCertificate Generates a self-signed certificate for 127.0.0.1 and a client configuration trusting it.
+func testCertificate(t *testing.T) (*tls.Config, *tls.Config) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	server := &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	client := &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+
+	return server, client
+}
+
+// readEmail Decodes a received message into its headers and its plain text and HTML bodies.
+func readEmail(t *testing.T, data string) (mail.Header, string, string) {
+	message, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	bodies := map[string]string{}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		body, _ := ioutil.ReadAll(part)
+		bodies[contentType] = string(body)
+	}
+
+	return message.Header, bodies["text/plain"], bodies["text/html"]
+}
+
+func TestSendEmailStartTLS(t *testing.T) {
+	serverTLS, clientTLS := testCertificate(t)
+	server := newSMTPStandIn(t, serverTLS, false)
+	defer server.Close()
+
+	cfg := config.Config{Notifiers: map[string]interface{}{"email": &EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     server.Port(),
+		Username: "clerk",
+		Password: "secret",
+		From:     "NVIDIA Clerk <clerk@example.com>",
+		To:       []string{"a@example.com", "B <b@example.com>"},
+		TLS:      EmailTLSStartTLS,
+	}}}
+
+	notifier, err := newEmailNotifier(cfg, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	notifier.(*emailNotifier).tls = clientTLS
+
+	err = notifier.Notify(context.Background(), testEvent())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	messages := server.Messages()
+	assert.Equal(t, 1, len(messages))
+	assert.True(t, messages[0].TLS)
+	assert.Equal(t, "\x00clerk\x00secret", messages[0].Auth)
+	assert.Equal(t, "clerk@example.com", messages[0].From)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, messages[0].To)
+
+	header, text, html := readEmail(t, messages[0].Data)
+	subject, _ := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	assert.Equal(t, "NVIDIA GEFORCE RTX 3080 (DEU, 699,00 €) is in stock", subject)
+	assert.Equal(t, `"NVIDIA Clerk" <clerk@example.com>`, header.Get("From"))
+
+	assert.Contains(t, text, "Price: 699,00 €\n")
+	assert.Contains(t, text, "Go to cart: https://store.nvidia.com/cart")
+	assert.Contains(t, html, `<img src="https://assets.nvidia.partners/images/png/nvidia-geforce-rtx-3080.png"`)
+	assert.Contains(t, html, `<a href="https://store.nvidia.com/cart"`)
+	assert.Contains(t, html, "<td>699,00 €</td>")
+}
+
+func TestSendEmailImplicitTLS(t *testing.T) {
+	serverTLS, clientTLS := testCertificate(t)
+	server := newSMTPStandIn(t, serverTLS, true)
+	defer server.Close()
+
+	cfg := config.Config{Notifiers: map[string]interface{}{"email": &EmailConfig{
+		Host: "127.0.0.1",
+		Port: server.Port(),
+		From: "clerk@example.com",
+		To:   []string{"a@example.com"},
+		TLS:  EmailTLSImplicit,
+	}}}
+
+	notifier, err := newEmailNotifier(cfg, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	notifier.(*emailNotifier).tls = clientTLS
+
+	err = notifier.(APINotifier).NotifyAPI(context.Background(), APIEvent{Name: "Store Session", Status: "offline"})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	messages := server.Messages()
+	assert.Equal(t, 1, len(messages))
+	assert.True(t, messages[0].TLS)
+	assert.Equal(t, "", messages[0].Auth, "no credentials configured")
+
+	header, text, html := readEmail(t, messages[0].Data)
+	assert.Equal(t, "NVIDIA API Store Session is now offline", header.Get("Subject"))
+	assert.Equal(t, "NVIDIA API Store Session is now offline\n\nStatus: offline\n", text)
+	assert.Contains(t, html, "<h2 style=\"color: #76b900;\">NVIDIA API Store Session</h2>")
+}
+
+func TestSendEmailWithoutStartTLS(t *testing.T) {
+	server := newSMTPStandIn(t, nil, false)
+	defer server.Close()
+
+	cfg := EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     server.Port(),
+		Username: "clerk",
+		Password: "secret",
+		From:     "clerk@example.com",
+		To:       []string{"a@example.com"},
+		TLS:      EmailTLSStartTLS,
+	}
+	message := EmailMessage{Subject: "test", Text: "test", HTML: "<p>test</p>"}
+
+	err := SendEmail(context.Background(), message, cfg, nil)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "doesn't support STARTTLS")
+
+	cfg.TLS = EmailTLSNone
+	assert.Nil(t, SendEmail(context.Background(), message, cfg, nil), "credentials may be sent unencrypted to localhost only")
+	assert.Equal(t, 1, len(server.Messages()))
+}
+
+func TestSendEmailCancelled(t *testing.T) {
+	// A server that accepts connections but never greets the client.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			bufio.NewReader(conn).ReadByte()
+			conn.Close()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	cfg := EmailConfig{Host: "127.0.0.1", Port: port, From: "clerk@example.com", To: []string{"a@example.com"}, TLS: EmailTLSNone}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = SendEmail(ctx, EmailMessage{Subject: "test"}, cfg, nil)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestEmailSettings(t *testing.T) {
+	defer os.Unsetenv("SMTP_TO")
+	defer os.Unsetenv("SMTP_TLS")
+	defer os.Unsetenv("SMTP_PASSWORD")
+	os.Setenv("SMTP_PASSWORD", "secret")
+
+	file := readTestFile(t, `update: false
+watches:
+  - regions: [USA]
+    models: [3080]
+notifiers:
+  email:
+    host: smtp.example.com
+    username: clerk
+    from: NVIDIA Clerk <clerk@example.com>
+    to: [a@example.com, b@example.com]
+`)
+
+	result, err := file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := &EmailConfig{
+		Host:     "smtp.example.com",
+		Port:     "587",
+		Username: "clerk",
+		Password: "secret",
+		From:     "NVIDIA Clerk <clerk@example.com>",
+		To:       []string{"a@example.com", "b@example.com"},
+		TLS:      EmailTLSStartTLS,
+	}
+	assert.Equal(t, expected, result.Notifiers["email"])
+
+	os.Setenv("SMTP_TO", "c@example.com, d@example.com")
+	os.Setenv("SMTP_TLS", EmailTLSImplicit)
+
+	result, err = file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, "465", result.Notifiers["email"].(*EmailConfig).Port)
+	assert.Equal(t, []string{"c@example.com", "d@example.com"}, result.Notifiers["email"].(*EmailConfig).To)
+}
+
+func TestEmailSettingsValidation(t *testing.T) {
+	file := readTestFile(t, `update: false
+watches:
+  - regions: [USA]
+    models: [3080]
+notifiers:
+  email:
+    port: smtp
+    from: clerk
+    to: [a@example.com, b]
+    tls: ssl
+`)
+
+	_, err := file.Config()
+	errs := fileErrors(t, err)
+
+	assert.Equal(t, 5, len(errs))
+	assert.Equal(t, "6: host is required, set it in the configuration file or with SMTP_HOST", errs[0])
+	assert.Contains(t, errs[1], "8: clerk: from must be an email address")
+	assert.Contains(t, errs[2], "9: b: to must be email addresses")
+	assert.Equal(t, "10: ssl: tls must be one of starttls, implicit or none", errs[3])
+	assert.Equal(t, "7: smtp: port must be a number between 1 and 65535", errs[4])
+
+	assert.Nil(t, file.Set("email", "false"))
+	_, err = file.Config()
+	assert.Nil(t, err)
+}
@@ -0,0 +1,98 @@
+package alert
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+// StockEvent represents a change in a watched products inventory status.
+type StockEvent struct {
+	Model          string
+	Region         string
+	SKU            string
+	Name           string
+	DisplayName    string
+	Price          string
+	Thumbnail      string
+	Status         string
+	PreviousStatus string
+	Timestamp      time.Time
+	CartURL        string
+
+	// PriceChange is KindPriceDrop or KindPriceIncrease for sale price changes and empty for inventory changes.
+	PriceChange   string
+	PreviousPrice string
+}
+
+// NewStockEvent Generates a StockEvent for a watched product from NVIDIAs product information.
+func NewStockEvent(watch config.Watch, product rest.Product, previousStatus string, cartURL string) StockEvent {
+	return StockEvent{
+		Model:          watch.Model,
+		Region:         watch.Region,
+		SKU:            watch.SKU,
+		Name:           product.Name,
+		DisplayName:    product.DisplayName,
+		Price:          product.Pricing.FormattedSalePriceWithQuantity,
+		Thumbnail:      product.ThumbnailImage,
+		Status:         product.InventoryStatus.Status,
+		PreviousStatus: previousStatus,
+		Timestamp:      time.Now(),
+		CartURL:        cartURL,
+	}
+}
+
+// NewPriceEvent Generates a StockEvent for a change in the sale price of a watched product.
+func NewPriceEvent(watch config.Watch, product rest.Product, previous rest.Pricing, cartURL string) StockEvent {
+	event := NewStockEvent(watch, product, product.InventoryStatus.Status, cartURL)
+	event.PreviousPrice = previous.FormattedSalePriceWithQuantity
+
+	event.PriceChange = KindPriceIncrease
+	if product.Pricing.SalePriceWithQuantity.Value < previous.SalePriceWithQuantity.Value {
+		event.PriceChange = KindPriceDrop
+	}
+
+	return event
+}
+
+// Title returns the display name of the product falling back to its name.
+func (e StockEvent) Title() string {
+	if strings.TrimSpace(e.DisplayName) != "" {
+		return strings.TrimSpace(e.DisplayName)
+	}
+
+	return e.Name
+}
+
+// Summary returns a short description of the product including its region and price when known.
+func (e StockEvent) Summary() string {
+	details := []string{}
+
+	if e.Region != "" {
+		details = append(details, e.Region)
+	}
+
+	if e.Price != "" {
+		details = append(details, e.Price)
+	}
+
+	if len(details) == 0 {
+		return e.Title()
+	}
+
+	return fmt.Sprintf("%s (%s)", e.Title(), strings.Join(details, ", "))
+}
+
+// StatusText Converts an NVIDIA inventory status E.X. PRODUCT_INVENTORY_IN_STOCK into readable text E.X. In Stock.
+func StatusText(status string) string {
+	words := strings.Fields(strings.ToLower(strings.Replace(strings.TrimPrefix(status, "PRODUCT_INVENTORY_"), "_", " ", -1)))
+
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,117 @@
+package alert
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEvent() StockEvent {
+	watch := config.Watch{Region: "DEU", Model: "3080", SKU: "5440853700", Locale: "de_de", NvidiaLocale: "de-de", Currency: "EUR"}
+	product := rest.Product{
+		Name:           "NVIDIA GEFORCE RTX 3080",
+		DisplayName:    "NVIDIA GEFORCE RTX 3080 ",
+		ThumbnailImage: "https://assets.nvidia.partners/images/png/nvidia-geforce-rtx-3080.png",
+		Pricing:        rest.Pricing{FormattedSalePriceWithQuantity: "699,00 €"},
+		InventoryStatus: rest.InventoryStatus{
+			Status: "PRODUCT_INVENTORY_IN_STOCK",
+		},
+	}
+
+	event := NewStockEvent(watch, product, "PRODUCT_INVENTORY_OUT_OF_STOCK", "https://store.nvidia.com/cart")
+	event.Timestamp = time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+
+	return event
+}
+
+func TestNewStockEvent(t *testing.T) {
+	event := testEvent()
+
+	assert.Equal(t, "3080", event.Model)
+	assert.Equal(t, "DEU", event.Region)
+	assert.Equal(t, "5440853700", event.SKU)
+	assert.Equal(t, "699,00 €", event.Price)
+	assert.Equal(t, "PRODUCT_INVENTORY_IN_STOCK", event.Status)
+	assert.Equal(t, "PRODUCT_INVENTORY_OUT_OF_STOCK", event.PreviousStatus)
+	assert.Equal(t, "NVIDIA GEFORCE RTX 3080 (DEU, 699,00 €)", event.Summary())
+}
+
+func TestNewPriceEvent(t *testing.T) {
+	watch := config.Watch{Region: "USA", Model: "3080", SKU: "5438481700"}
+	product := rest.Product{
+		Name:            "NVIDIA GEFORCE RTX 3080",
+		Pricing:         rest.Pricing{SalePriceWithQuantity: rest.Price{Currency: "USD", Value: 64900}, FormattedSalePriceWithQuantity: "$649.00"},
+		InventoryStatus: rest.InventoryStatus{Status: "PRODUCT_INVENTORY_OUT_OF_STOCK"},
+	}
+	previous := rest.Pricing{SalePriceWithQuantity: rest.Price{Currency: "USD", Value: 69900}, FormattedSalePriceWithQuantity: "$699.00"}
+
+	event := NewPriceEvent(watch, product, previous, "https://store.nvidia.com/cart")
+	assert.Equal(t, KindPriceDrop, event.Kind())
+	assert.Equal(t, "$699.00", event.PreviousPrice)
+	assert.Equal(t, "$649.00", event.Price)
+
+	templates, _ := NewTemplates(config.Config{}, "telegram")
+	message, err := templates.Render(event.Kind(), event)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	assert.Equal(t, "NVIDIA GEFORCE RTX 3080 (USA) price dropped from $699.00 to $649.00: https://store.nvidia.com/cart", message)
+
+	event = NewPriceEvent(watch, product, rest.Pricing{SalePriceWithQuantity: rest.Price{Currency: "USD", Value: 59900}}, "")
+	assert.Equal(t, KindPriceIncrease, event.Kind())
+}
+
+func TestStatusText(t *testing.T) {
+	assert.Equal(t, "In Stock", StatusText("PRODUCT_INVENTORY_IN_STOCK"))
+	assert.Equal(t, "Out Of Stock", StatusText("PRODUCT_INVENTORY_OUT_OF_STOCK"))
+	assert.Equal(t, "", StatusText(""))
+}
+
+func TestDiscordProductMessageEvent(t *testing.T) {
+	templates, err := NewTemplates(config.Config{}, "discord")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	content, err := templates.Render(KindInStock, testEvent())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	message := DiscordProductMessage{}
+	message.SetEvent(testEvent(), content)
+
+	payload, err := message.JSON()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	body := struct {
+		Content string `json:"content"`
+		Embeds  []struct {
+			Title     string `json:"title"`
+			URL       string `json:"url"`
+			Timestamp string `json:"timestamp"`
+			Thumbnail struct {
+				URL string `json:"url"`
+			} `json:"thumbnail"`
+			Fields []struct {
+				Name  string `json:"name"`
+				Value string `json:"value"`
+			} `json:"fields"`
+		} `json:"embeds"`
+	}{}
+	json.Unmarshal(payload, &body)
+
+	assert.Equal(t, "In Stock: NVIDIA GEFORCE RTX 3080 (DEU, 699,00 €) https://store.nvidia.com/cart", body.Content)
+	assert.Equal(t, "NVIDIA GEFORCE RTX 3080", body.Embeds[0].Title)
+	assert.Equal(t, "https://store.nvidia.com/cart", body.Embeds[0].URL)
+	assert.Equal(t, "2020-09-17T13:00:00Z", body.Embeds[0].Timestamp)
+	assert.Equal(t, "https://assets.nvidia.partners/images/png/nvidia-geforce-rtx-3080.png", body.Embeds[0].Thumbnail.URL)
+	assert.Equal(t, 3, len(body.Embeds[0].Fields))
+	assert.Equal(t, "699,00 €", body.Embeds[0].Fields[1].Value)
+}
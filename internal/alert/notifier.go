@@ -0,0 +1,116 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+// Notifier represents a notification channel that can deliver stock events.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, event StockEvent) error
+}
+
+// APINotifier represents a Notifier that can also deliver changes in the status of NVIDIAs APIs.
+type APINotifier interface {
+	Notifier
+	NotifyAPI(ctx context.Context, event APIEvent) error
+}
+
+// DeadlineNotifier represents a Notifier that retries deliveries itself and says how long that can take.
+type DeadlineNotifier interface {
+	Notifier
+
+	// Deadline Gets the longest time delivering a single event can take including every retry.
+	Deadline() time.Duration
+}
+
+// Factory creates a Notifier from configuration, returning a nil Notifier when the channel isn't enabled.
+type Factory func(config config.Config, client *http.Client) (Notifier, error)
+
+// Channel represents a registered notification channel, the name doubles as its command line flag.
+type Channel struct {
+	Name  string
+	Usage string
+	New   Factory
+
+	// Settings environment variables and section of the configuration file enabling the channel, its name is always Name.
+	Settings config.Section
+}
+
+var registry = map[string]Channel{}
+
+// Register Adds a notification channel and its settings to the registry, channels register themselves from init.
+func Register(channel Channel) {
+	if _, ok := registry[channel.Name]; ok {
+		panic(fmt.Sprintf("alert: channel %s registered twice", channel.Name))
+	}
+
+	channel.Settings.Name = channel.Name
+	config.RegisterSection(channel.Settings)
+
+	registry[channel.Name] = channel
+}
+
+// Channels Gets every registered notification channel ordered by name.
+func Channels() []Channel {
+	channels := []Channel{}
+
+	for _, channel := range registry {
+		channels = append(channels, channel)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+
+	return channels
+}
+
+// Enabled Validates message templates and creates a Notifier for every registered channel enabled in the configuration.
+func Enabled(config config.Config, client *http.Client) ([]Notifier, error) {
+	err := ValidateTemplates(config)
+	if err != nil {
+		return nil, err
+	}
+
+	notifiers := []Notifier{}
+
+	for _, channel := range Channels() {
+		notifier, err := channel.New(config, client)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", channel.Name, err)
+		}
+
+		if notifier != nil {
+			notifiers = append(notifiers, notifier)
+		}
+	}
+
+	return notifiers, nil
+}
+
+// postJSON POSTs a JSON payload with any extra headers, returning an *rest.HTTPError for responses other than 2XX.
+func postJSON(ctx context.Context, client *http.Client, url string, payload []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	return rest.CheckResponse(r)
+}
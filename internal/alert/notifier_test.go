@@ -0,0 +1,114 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannels(t *testing.T) {
+	names := []string{}
+	for _, channel := range Channels() {
+		names = append(names, channel.Name)
+	}
+
+	assert.Equal(t, []string{"desktop", "discord", "email", "slack", "sms", "telegram", "twitter", "webhook"}, names)
+}
+
+func TestRegisterTwice(t *testing.T) {
+	assert.Panics(t, func() {
+		Register(Channel{Name: "discord", New: newDiscordNotifier})
+	})
+}
+
+func TestEnabled(t *testing.T) {
+	requests := []string{}
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		requests = append(requests, req.URL.String())
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`OK`)),
+			Header:     make(http.Header),
+		}
+	})
+
+	cfg := config.Config{Notifiers: map[string]interface{}{
+		"discord":  &DiscordConfig{WebhookURL: "http://testurl/webhook/"},
+		"telegram": &TelegramConfig{APIKey: "1", ChatID: "1"},
+	}}
+
+	notifiers, err := Enabled(cfg, client)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	assert.Equal(t, 2, len(notifiers))
+	assert.Equal(t, "discord", notifiers[0].Name())
+	assert.Equal(t, "telegram", notifiers[1].Name())
+
+	for _, notifier := range notifiers {
+		err := notifier.Notify(context.Background(), StockEvent{Name: "NVIDIA GEFORCE RTX 3080", CartURL: "http://testurl/cart/"})
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+	}
+
+	assert.Equal(t, []string{"http://testurl/webhook/", "https://api.telegram.org/bot1/sendMessage"}, requests)
+}
+
+func TestChannelSettings(t *testing.T) {
+	tests := map[string]struct {
+		environment map[string]string
+		expected    interface{}
+	}{
+		"sms": {
+			environment: map[string]string{"TWILIO_ACCOUNT_SID": "1", "TWILIO_TOKEN": "2", "TWILIO_SOURCE_NUMBER": "3", "TWILIO_DESTINATION_NUMBER": "4"},
+			expected:    &TwilioConfig{AccountSID: "1", Token: "2", SourceNumber: "3", DestinationNumber: "4"},
+		},
+		"discord": {
+			environment: map[string]string{"DISCORD_WEBHOOK_URL": "1"},
+			expected:    &DiscordConfig{WebhookURL: "1"},
+		},
+		"slack": {
+			environment: map[string]string{"SLACK_WEBHOOK_URL": "1"},
+			expected:    &SlackConfig{WebhookURL: "1"},
+		},
+		"twitter": {
+			environment: map[string]string{"TWITTER_CONSUMER_KEY": "1", "TWITTER_CONSUMER_SECRET": "2", "TWITTER_ACCESS_TOKEN": "3", "TWITTER_ACCESS_SECRET": "4"},
+			expected:    &TwitterConfig{ConsumerKey: "1", ConsumerSecret: "2", AccessToken: "3", AccessSecret: "4"},
+		},
+		"telegram": {
+			environment: map[string]string{"TELEGRAM_API_KEY": "1", "TELEGRAM_CHAT_ID": "2"},
+			expected:    &TelegramConfig{APIKey: "1", ChatID: "2"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for key := range test.environment {
+				os.Unsetenv(key)
+			}
+
+			_, err := config.Get([]string{"USA"}, []string{"3080"}, 0, []string{name}, false, false)
+			assert.Contains(t, err.Error(), "environment variable not found")
+
+			for key, value := range test.environment {
+				defer os.Unsetenv(key)
+				os.Setenv(key, value)
+			}
+
+			result, err := config.Get([]string{"USA"}, []string{"3080"}, 0, []string{name}, false, false)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			assert.Equal(t, map[string]interface{}{name: test.expected}, result.Notifiers)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+)
+
+func init() {
+	Register(Channel{
+		Name:     "slack",
+		Usage:    "Enable Slack incoming webhook notifications for whenever SKU is in stock.",
+		New:      newSlackNotifier,
+		Settings: config.Section{New: func() interface{} { return &SlackConfig{} }, Resolve: resolveSlack},
+	})
+}
+
+// SlackConfig represents the Slack incoming webhook messages are sent to.
+type SlackConfig struct {
+	WebhookURL string `yaml:"webhook_url"`
+}
+
+// resolveSlack Reads SLACK_WEBHOOK_URL over the value of the configuration file.
+func resolveSlack(settings interface{}, r *config.Resolver) {
+	c := settings.(*SlackConfig)
+	r.Required("webhook_url", "SLACK_WEBHOOK_URL", &c.WebhookURL)
+}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackNotifier delivers stock events as Slack incoming webhook messages.
+type slackNotifier struct {
+	config    SlackConfig
+	templates Templates
+	client    *http.Client
+}
+
+func newSlackNotifier(cfg config.Config, client *http.Client) (Notifier, error) {
+	settings, ok := cfg.Notifiers["slack"].(*SlackConfig)
+	if ok == false {
+		return nil, nil
+	}
+
+	templates, err := NewTemplates(cfg, "slack")
+	if err != nil {
+		return nil, err
+	}
+
+	return &slackNotifier{*settings, templates, client}, nil
+}
+
+// Name returns the channel name of the notifier.
+func (n *slackNotifier) Name() string {
+	return "slack"
+}
+
+// Notify sends a Slack message for a stock event.
+func (n *slackNotifier) Notify(ctx context.Context, event StockEvent) error {
+	content, err := n.templates.Render(event.Kind(), event)
+	if err != nil {
+		return err
+	}
+
+	message := SlackMessage{}
+	message.SetEvent(event, content)
+
+	return SendSlackMessage(ctx, &message, n.config, n.client)
+}
+
+// SlackMessage represents a Slack message made of Block Kit blocks.
+type SlackMessage struct {
+	text  string
+	event *StockEvent
+}
+
+// Get returns the plain text of the SlackMessage shown in notifications.
+func (s *SlackMessage) Get() string {
+	return s.text
+}
+
+// SetEvent takes in a StockEvent and its rendered message and adds blocks with the products price, region, image and a button to its cart
+func (s *SlackMessage) SetEvent(event StockEvent, content string) {
+	s.event = &event
+	s.text = content
+}
+
+// JSON returns the JSON encoded bytes of a SlackMessage
+func (s *SlackMessage) JSON() ([]byte, error) {
+	body := map[string]interface{}{"text": s.Get()}
+	if s.event != nil {
+		body["blocks"] = slackBlocks(*s.event, s.Get())
+	}
+
+	json, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return json, nil
+}
+
+// slackBlocks Generates Block Kit blocks describing a StockEvent.
+func slackBlocks(event StockEvent, content string) []map[string]interface{} {
+	header := map[string]interface{}{
+		"type": "section",
+		"text": slackText(fmt.Sprintf("*%s*\n%s", slackEscaper.Replace(event.Title()), slackEscaper.Replace(content))),
+	}
+
+	if event.Thumbnail != "" {
+		header["accessory"] = map[string]string{"type": "image", "image_url": event.Thumbnail, "alt_text": event.Title()}
+	}
+
+	fields := []map[string]string{
+		slackText(fmt.Sprintf("*Status*\n%s", StatusText(event.Status))),
+	}
+
+	if event.Price != "" {
+		fields = append(fields, slackText(fmt.Sprintf("*Price*\n%s", slackEscaper.Replace(event.Price))))
+	}
+
+	if event.PreviousPrice != "" {
+		fields = append(fields, slackText(fmt.Sprintf("*Previous Price*\n%s", slackEscaper.Replace(event.PreviousPrice))))
+	}
+
+	if event.Region != "" {
+		fields = append(fields, slackText(fmt.Sprintf("*Region*\n%s", event.Region)))
+	}
+
+	blocks := []map[string]interface{}{
+		header,
+		{"type": "section", "fields": fields},
+	}
+
+	if strings.HasPrefix(event.CartURL, "http") {
+		label := "View product"
+		if event.Kind() == KindInStock {
+			label = "Go to cart"
+		}
+
+		button := map[string]interface{}{
+			"type":      "button",
+			"text":      map[string]string{"type": "plain_text", "text": label},
+			"url":       event.CartURL,
+			"action_id": "cart",
+			"style":     "primary",
+		}
+		blocks = append(blocks, map[string]interface{}{"type": "actions", "elements": []interface{}{button}})
+	}
+
+	if event.Timestamp.IsZero() == false {
+		date := fmt.Sprintf("<!date^%d^{date_short_pretty} {time_secs}|%s>", event.Timestamp.Unix(), event.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC"))
+		blocks = append(blocks, map[string]interface{}{"type": "context", "elements": []interface{}{slackText(date)}})
+	}
+
+	return blocks
+}
+
+// slackText Generates a Block Kit mrkdwn text object.
+func slackText(text string) map[string]string {
+	return map[string]string{"type": "mrkdwn", "text": text}
+}
+
+// SendSlackMessage Sends a notification message to a Slack incoming webhook.
+func SendSlackMessage(ctx context.Context, message *SlackMessage, config SlackConfig, client *http.Client) error {
+	json, err := message.JSON()
+	if err != nil {
+		return err
+	}
+
+	return postJSON(ctx, client, config.WebhookURL, json, nil)
+}
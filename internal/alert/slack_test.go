@@ -0,0 +1,108 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+// slackBody represents the parts of a Slack webhook payload checked by tests.
+type slackBody struct {
+	Text   string `json:"text"`
+	Blocks []struct {
+		Type string `json:"type"`
+		Text struct {
+			Text string `json:"text"`
+		} `json:"text"`
+		Accessory struct {
+			ImageURL string `json:"image_url"`
+		} `json:"accessory"`
+		Fields []struct {
+			Text string `json:"text"`
+		} `json:"fields"`
+		Elements []struct {
+			Text interface{} `json:"text"`
+			URL  string      `json:"url"`
+		} `json:"elements"`
+	} `json:"blocks"`
+}
+
+func TestSendSlackMessage(t *testing.T) {
+	var body slackBody
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		if req.URL.String() == "https://hooks.slack.com/services/T0/B0/X" {
+			assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+			json.NewDecoder(req.Body).Decode(&body)
+
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`ok`)),
+				Header:     make(http.Header),
+			}
+		}
+
+		return &http.Response{
+			StatusCode: 503,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`OK`)),
+			Header:     make(http.Header),
+		}
+	})
+
+	cfg := config.Config{Notifiers: map[string]interface{}{"slack": &SlackConfig{WebhookURL: "https://hooks.slack.com/services/T0/B0/X"}}}
+
+	notifier, err := newSlackNotifier(cfg, client)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = notifier.Notify(context.Background(), testEvent())
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	assert.Equal(t, "In Stock: NVIDIA GEFORCE RTX 3080 (DEU, 699,00 €)", body.Text)
+	assert.Equal(t, 4, len(body.Blocks))
+	assert.Equal(t, "*NVIDIA GEFORCE RTX 3080*\nIn Stock: NVIDIA GEFORCE RTX 3080 (DEU, 699,00 €)", body.Blocks[0].Text.Text)
+	assert.Equal(t, "https://assets.nvidia.partners/images/png/nvidia-geforce-rtx-3080.png", body.Blocks[0].Accessory.ImageURL)
+	assert.Equal(t, []string{"*Status*\nIn Stock", "*Price*\n699,00 €", "*Region*\nDEU"}, []string{body.Blocks[1].Fields[0].Text, body.Blocks[1].Fields[1].Text, body.Blocks[1].Fields[2].Text})
+	assert.Equal(t, "actions", body.Blocks[2].Type)
+	assert.Equal(t, "https://store.nvidia.com/cart", body.Blocks[2].Elements[0].URL)
+	assert.Equal(t, map[string]interface{}{"type": "plain_text", "text": "Go to cart"}, body.Blocks[2].Elements[0].Text)
+	assert.Equal(t, "context", body.Blocks[3].Type)
+
+	err = SendSlackMessage(context.Background(), &SlackMessage{text: "test"}, SlackConfig{WebhookURL: "https://hooks.slack.com/services/invalid"}, client)
+	if errors.Is(err, rest.ErrServerError) == false {
+		t.Errorf("Expected server error, got %v", err)
+	}
+}
+
+func TestSlackMessageEvent(t *testing.T) {
+	event := testEvent()
+	event.DisplayName = "RTX 3080 <Founders & Friends>"
+	event.Thumbnail = ""
+	event.CartURL = "Ready for purchase"
+
+	message := SlackMessage{}
+	message.SetEvent(event, "In stock")
+
+	payload, err := message.JSON()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	body := slackBody{}
+	json.Unmarshal(payload, &body)
+
+	assert.Equal(t, "*RTX 3080 &lt;Founders &amp; Friends&gt;*\nIn stock", body.Blocks[0].Text.Text, "mrkdwn control characters are escaped")
+	assert.Equal(t, "", body.Blocks[0].Accessory.ImageURL)
+	assert.Equal(t, 3, len(body.Blocks), "no button without a cart link")
+	assert.Equal(t, "context", body.Blocks[2].Type)
+}
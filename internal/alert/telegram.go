@@ -1,7 +1,7 @@
 package alert
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,30 +9,73 @@ import (
 	"github.com/ianmarmour/nvidia-clerk/internal/config"
 )
 
-//SendTelegramMessage Sends a notification message to a Telegram Webhook.
-func SendTelegramMessage(item string, nvidiaURL string, config config.TelegramConfig, client *http.Client) error {
-	text := item + " Ready for Purchase: " + nvidiaURL
-	body := map[string]interface{}{"chat_id": config.ChatID, "text": text, "disable_web_page_preview": true}
+func init() {
+	Register(Channel{
+		Name:     "telegram",
+		Usage:    "Enable Telegram webhook notifications for whenever SKU is in stock.",
+		New:      newTelegramNotifier,
+		Settings: config.Section{New: func() interface{} { return &TelegramConfig{} }, Resolve: resolveTelegram},
+	})
+}
 
-	payload, err := json.Marshal(body)
+// TelegramConfig represents the Telegram bot messages are sent from and the chat they are sent to.
+type TelegramConfig struct {
+	APIKey string `yaml:"api_key"`
+	ChatID string `yaml:"chat_id"`
+}
+
+// resolveTelegram Reads the TELEGRAM_ environment variables over the values of the configuration file.
+func resolveTelegram(settings interface{}, r *config.Resolver) {
+	c := settings.(*TelegramConfig)
+	r.Required("api_key", "TELEGRAM_API_KEY", &c.APIKey)
+	r.Required("chat_id", "TELEGRAM_CHAT_ID", &c.ChatID)
+}
+
+// telegramNotifier delivers stock events as Telegram messages.
+type telegramNotifier struct {
+	config    TelegramConfig
+	templates Templates
+	client    *http.Client
+}
+
+func newTelegramNotifier(cfg config.Config, client *http.Client) (Notifier, error) {
+	settings, ok := cfg.Notifiers["telegram"].(*TelegramConfig)
+	if ok == false {
+		return nil, nil
+	}
+
+	templates, err := NewTemplates(cfg, "telegram")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// We're required to disable web page previews to ensure that the cart links don't get invalidated
-	req, err := http.NewRequest("POST", fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", config.APIKey), bytes.NewBuffer(payload))
+	return &telegramNotifier{*settings, templates, client}, nil
+}
+
+// Name returns the channel name of the notifier.
+func (n *telegramNotifier) Name() string {
+	return "telegram"
+}
+
+// Notify sends a Telegram message for a stock event.
+func (n *telegramNotifier) Notify(ctx context.Context, event StockEvent) error {
+	message, err := n.templates.Render(event.Kind(), event)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("content-type", "application/json")
+	return SendTelegramMessage(ctx, message, n.config, n.client)
+}
 
-	r, err := client.Do(req)
+//SendTelegramMessage Sends a notification message to a Telegram Webhook.
+func SendTelegramMessage(ctx context.Context, message string, config TelegramConfig, client *http.Client) error {
+	body := map[string]interface{}{"chat_id": config.ChatID, "text": message, "disable_web_page_preview": true}
+
+	payload, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
 
-	defer r.Body.Close()
-
-	return nil
+	// We're required to disable web page previews to ensure that the cart links don't get invalidated
+	return postJSON(ctx, client, fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", config.APIKey), payload, nil)
 }
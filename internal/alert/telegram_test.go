@@ -2,11 +2,10 @@ package alert
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"testing"
-
-	"github.com/ianmarmour/nvidia-clerk/internal/config"
 )
 
 func TestSendTelegramMessage(t *testing.T) {
@@ -26,12 +25,12 @@ func TestSendTelegramMessage(t *testing.T) {
 		}
 	})
 
-	cfg := config.TelegramConfig{
+	cfg := TelegramConfig{
 		APIKey: "1",
 		ChatID: "1",
 	}
 
-	err := SendTelegramMessage("FAKE_SKU_NUMBER", "fakeUrl", cfg, client)
+	err := SendTelegramMessage(context.Background(), "FAKE_SKU_NUMBER Ready for Purchase: fakeUrl", cfg, client)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -0,0 +1,225 @@
+package alert
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+// Event kinds used to select a message template.
+const (
+	KindInStock       = "in_stock"
+	KindOutOfStock    = "out_of_stock"
+	KindAPIOnline     = "api_online"
+	KindAPIOffline    = "api_offline"
+	KindPriceDrop     = "price_drop"
+	KindPriceIncrease = "price_increase"
+)
+
+// APIEvent represents a change in the status of one of NVIDIAs APIs.
+type APIEvent struct {
+	Name   string
+	Status string
+}
+
+// Kind returns the template kind for an API status change.
+func (e APIEvent) Kind() string {
+	if e.Status == "online" {
+		return KindAPIOnline
+	}
+
+	return KindAPIOffline
+}
+
+// Kind returns the template kind for a stock event, every status that can't be purchased uses KindOutOfStock.
+func (e StockEvent) Kind() string {
+	if e.PriceChange != "" {
+		return e.PriceChange
+	}
+
+	switch e.Status {
+	case rest.StatusInStock, rest.StatusLimitedAvailability:
+		return KindInStock
+	}
+
+	return KindOutOfStock
+}
+
+var templateFuncs = template.FuncMap{
+	"status": StatusText,
+}
+
+// defaultTemplates Message templates used when a channel and kind aren't configured, keyed by channel then kind.
+var defaultTemplates = map[string]map[string]string{
+	"*": {
+		KindInStock:       `{{.Summary}} Ready for Purchase: {{.CartURL}}`,
+		KindOutOfStock:    `{{.Summary}} is now {{status .Status}}`,
+		KindAPIOnline:     `NVIDIA API {{.Name}} is now {{.Status}}`,
+		KindAPIOffline:    `NVIDIA API {{.Name}} is now {{.Status}}`,
+		KindPriceDrop:     `{{.Title}} ({{.Region}}) price dropped from {{.PreviousPrice}} to {{.Price}}: {{.CartURL}}`,
+		KindPriceIncrease: `{{.Title}} ({{.Region}}) price increased from {{.PreviousPrice}} to {{.Price}}: {{.CartURL}}`,
+	},
+	"sms": {
+		KindInStock: `{{.Summary}} Ready for Purchase: .{{.CartURL}}.`,
+	},
+	"desktop": {
+		KindInStock:    `{{.Summary}} Is ready for checkout`,
+		KindOutOfStock: `{{.Summary}} Is {{status .Status}}`,
+	},
+	"discord": {
+		KindInStock:    `{{status .Status}}: {{.Summary}} {{.CartURL}}`,
+		KindOutOfStock: `{{status .Status}}: {{.Summary}} {{.CartURL}}`,
+	},
+	"email": {
+		KindInStock:       `{{.Summary}} is in stock`,
+		KindPriceDrop:     `{{.Title}} ({{.Region}}) price dropped from {{.PreviousPrice}} to {{.Price}}`,
+		KindPriceIncrease: `{{.Title}} ({{.Region}}) price increased from {{.PreviousPrice}} to {{.Price}}`,
+	},
+	"slack": {
+		KindInStock:    `{{status .Status}}: {{.Summary}}`,
+		KindOutOfStock: `{{status .Status}}: {{.Summary}}`,
+	},
+}
+
+// Templates represents the parsed message templates of a single channel keyed by event kind.
+type Templates map[string]*template.Template
+
+// NewTemplates Parses the message templates for a channel, configured templates take precedence over the defaults.
+func NewTemplates(cfg config.Config, channel string) (Templates, error) {
+	templates := Templates{}
+
+	for _, kind := range kinds() {
+		text, ok := cfg.Templates[channel][kind]
+		if ok == false {
+			text, ok = defaultTemplates[channel][kind]
+		}
+		if ok == false {
+			text = defaultTemplates["*"][kind]
+		}
+
+		t, err := template.New(fmt.Sprintf("templates.%s.%s", channel, kind)).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+
+		err = t.Execute(&bytes.Buffer{}, sampleData(kind))
+		if err != nil {
+			return nil, err
+		}
+
+		templates[kind] = t
+	}
+
+	return templates, nil
+}
+
+// Render Executes the template for an event kind, data is a StockEvent or APIEvent.
+func (t Templates) Render(kind string, data interface{}) (string, error) {
+	tmpl, ok := t[kind]
+	if ok == false {
+		return "", fmt.Errorf("no template for %s", kind)
+	}
+
+	var b bytes.Buffer
+	err := tmpl.Execute(&b, data)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+// ValidateTemplates Checks every configured template parses and renders, reporting all problems at once.
+func ValidateTemplates(cfg config.Config) error {
+	problems := []string{}
+
+	channels := []string{}
+	for channel := range cfg.Templates {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	for _, channel := range channels {
+		if _, ok := registry[channel]; ok == false {
+			problems = append(problems, fmt.Sprintf("templates.%s: unknown channel, choose one of %v", channel, channelNames()))
+			continue
+		}
+
+		for kind := range cfg.Templates[channel] {
+			if contains(kinds(), kind) == false {
+				problems = append(problems, fmt.Sprintf("templates.%s.%s: unknown event kind, choose one of %v", channel, kind, kinds()))
+			}
+		}
+
+		_, err := NewTemplates(cfg, channel)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid message templates:\n%s", strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+// sampleData Generates example template data used to validate templates at startup.
+func sampleData(kind string) interface{} {
+	switch kind {
+	case KindAPIOnline:
+		return APIEvent{Name: "Store Session", Status: "online"}
+	case KindAPIOffline:
+		return APIEvent{Name: "Store Session", Status: "offline"}
+	}
+
+	event := StockEvent{
+		Model:          "3080",
+		Region:         "USA",
+		SKU:            "5438481700",
+		Name:           "NVIDIA GEFORCE RTX 3080",
+		DisplayName:    "NVIDIA GEFORCE RTX 3080",
+		Price:          "$699.00",
+		Thumbnail:      "https://assets.nvidia.partners/images/png/nvidia-geforce-rtx-3080.png",
+		Status:         "PRODUCT_INVENTORY_IN_STOCK",
+		PreviousStatus: "PRODUCT_INVENTORY_OUT_OF_STOCK",
+		Timestamp:      time.Now(),
+		CartURL:        "https://www.nvidia.com/",
+	}
+
+	if kind == KindPriceDrop || kind == KindPriceIncrease {
+		event.PriceChange = kind
+		event.PreviousPrice = "$749.00"
+	}
+
+	return event
+}
+
+func kinds() []string {
+	return []string{KindInStock, KindOutOfStock, KindAPIOnline, KindAPIOffline, KindPriceDrop, KindPriceIncrease}
+}
+
+func channelNames() []string {
+	names := []string{}
+	for _, channel := range Channels() {
+		names = append(names, channel.Name)
+	}
+
+	return names
+}
+
+// contains Determins if a string exists in a slice of strings.
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
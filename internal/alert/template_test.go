@@ -0,0 +1,83 @@
+package alert
+
+import (
+	"testing"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultTemplates(t *testing.T) {
+	event := StockEvent{Name: "2080", Status: "PRODUCT_INVENTORY_IN_STOCK", CartURL: "fakeurl"}
+
+	tests := map[string]string{
+		"sms":      "2080 Ready for Purchase: .fakeurl.",
+		"twitter":  "2080 Ready for Purchase: fakeurl",
+		"telegram": "2080 Ready for Purchase: fakeurl",
+		"desktop":  "2080 Is ready for checkout",
+		"discord":  "In Stock: 2080 fakeurl",
+	}
+
+	for channel, expected := range tests {
+		templates, err := NewTemplates(config.Config{}, channel)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		message, err := templates.Render(event.Kind(), event)
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+		assert.Equal(t, expected, message, channel)
+	}
+
+	templates, _ := NewTemplates(config.Config{}, "discord")
+	message, err := templates.Render(KindAPIOffline, APIEvent{Name: "Store Session", Status: "offline"})
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	assert.Equal(t, "NVIDIA API Store Session is now offline", message)
+}
+
+func TestConfiguredTemplates(t *testing.T) {
+	cfg := config.Config{
+		Templates: map[string]map[string]string{
+			"telegram": {KindOutOfStock: "{{.Model}} sold out in {{.Region}} at {{.Price}}"},
+		},
+	}
+
+	templates, err := NewTemplates(cfg, "telegram")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	event := StockEvent{Model: "3080", Region: "DEU", Price: "699,00 €", Status: "PRODUCT_INVENTORY_OUT_OF_STOCK"}
+	message, err := templates.Render(event.Kind(), event)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	assert.Equal(t, "3080 sold out in DEU at 699,00 €", message)
+}
+
+func TestValidateTemplates(t *testing.T) {
+	cfg := config.Config{
+		Templates: map[string]map[string]string{
+			"pager":    {KindInStock: "{{.Name}}"},
+			"discord":  {"restock": "{{.Name}}"},
+			"sms":      {KindInStock: "{{.Name"},
+			"telegram": {KindAPIOnline: "{{.Summary}}"},
+		},
+	}
+
+	err := ValidateTemplates(cfg)
+	if err == nil {
+		t.Fatalf("Expected validation error")
+	}
+
+	assert.Contains(t, err.Error(), "templates.pager: unknown channel")
+	assert.Contains(t, err.Error(), "templates.discord.restock: unknown event kind")
+	assert.Contains(t, err.Error(), "templates.sms.in_stock")
+	assert.Contains(t, err.Error(), "templates.telegram.api_online")
+
+	assert.Nil(t, ValidateTemplates(config.Config{}))
+}
@@ -1,16 +1,81 @@
 package alert
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os/exec"
+	"runtime"
 
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
 	"github.com/ianmarmour/nvidia-clerk/third_party/toast"
 )
 
 var execCommand = exec.Command
 
-func linuxToast(name string) error {
-	err := execCommand("notify-send", "NVIDIA Clerk", fmt.Sprintf("%s Is ready for checkout", name), "-u", "critical").Start()
+func init() {
+	Register(Channel{
+		Name:     "desktop",
+		Usage:    "Enable desktop notifications, disabled by default.",
+		New:      newToastNotifier,
+		Settings: config.Section{New: func() interface{} { return &ToastConfig{} }, Resolve: resolveToast},
+	})
+}
+
+// ToastConfig represents the platform desktop notifications are shown on, the configuration file only switches them on.
+type ToastConfig struct {
+	OS string `yaml:"-"`
+}
+
+// resolveToast Sets the platform to this one when desktop notifications are supported on it.
+func resolveToast(settings interface{}, r *config.Resolver) {
+	c := settings.(*ToastConfig)
+
+	switch runtime.GOOS {
+	case "linux", "windows", "darwin":
+		c.OS = runtime.GOOS
+	default:
+		r.Report("unsupported platform")
+	}
+}
+
+// toastNotifier delivers stock events as desktop notifications.
+type toastNotifier struct {
+	os        string
+	templates Templates
+}
+
+func newToastNotifier(cfg config.Config, client *http.Client) (Notifier, error) {
+	settings, ok := cfg.Notifiers["desktop"].(*ToastConfig)
+	if ok == false {
+		return nil, nil
+	}
+
+	templates, err := NewTemplates(cfg, "desktop")
+	if err != nil {
+		return nil, err
+	}
+
+	return &toastNotifier{settings.OS, templates}, nil
+}
+
+// Name returns the channel name of the notifier.
+func (n *toastNotifier) Name() string {
+	return "desktop"
+}
+
+// Notify shows a desktop notification for a stock event.
+func (n *toastNotifier) Notify(ctx context.Context, event StockEvent) error {
+	message, err := n.templates.Render(event.Kind(), event)
+	if err != nil {
+		return err
+	}
+
+	return SendToast(n.os, message)
+}
+
+func linuxToast(message string) error {
+	err := execCommand("notify-send", "NVIDIA Clerk", message, "-u", "critical").Start()
 	if err != nil {
 		return err
 	}
@@ -18,9 +83,15 @@ func linuxToast(name string) error {
 	return nil
 }
 
-func darwinToast(name string) error {
-	notification := fmt.Sprintf("display notification \"NVIDIA Clerk\" with title \"NVIDIA Clerk Inventory Alert\" subtitle \"%s\" sound name \"default\"", fmt.Sprintf("%s Is ready for checkout", name))
-	err := execCommand("osascript", "-e", notification).Start()
+// darwinScript Shows a notification with its subtitle passed as an argument so quotes and backslashes in messages aren't interpreted as AppleScript.
+var darwinScript = []string{
+	"-e", "on run argv",
+	"-e", "display notification \"NVIDIA Clerk\" with title \"NVIDIA Clerk Inventory Alert\" subtitle (item 1 of argv) sound name \"default\"",
+	"-e", "end run",
+}
+
+func darwinToast(message string) error {
+	err := execCommand("osascript", append(append([]string{}, darwinScript...), message)...).Start()
 	if err != nil {
 		return err
 	}
@@ -28,11 +99,11 @@ func darwinToast(name string) error {
 	return nil
 }
 
-func windowsToast(name string) error {
+func windowsToast(message string) error {
 	notification := toast.Notification{
 		AppID:    "NVIDIA Clerk",
 		Title:    "NVIDIA Clerk Inventory Alert",
-		Message:  fmt.Sprintf("%s Is ready for checkout", name),
+		Message:  message,
 		Duration: "long",
 	}
 
@@ -45,16 +116,16 @@ func windowsToast(name string) error {
 }
 
 // SendToast Sends a Toast alert for desktop notifications.
-func SendToast(os string, name string) error {
+func SendToast(os string, message string) error {
 	var err error
 
 	switch os {
 	case "linux":
-		err = linuxToast(name)
+		err = linuxToast(message)
 	case "windows":
-		err = windowsToast(name)
+		err = windowsToast(message)
 	case "darwin":
-		err = darwinToast(name)
+		err = darwinToast(message)
 	default:
 		err = fmt.Errorf("unsupported platform")
 	}
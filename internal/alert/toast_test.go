@@ -4,6 +4,8 @@ import (
 	"os"
 	"os/exec"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func fakeExecCommand(command string, args ...string) *exec.Cmd {
@@ -18,13 +20,35 @@ func TestSendToast(t *testing.T) {
 	execCommand = fakeExecCommand
 	defer func() { execCommand = exec.Command }()
 
-	err := SendToast("linux", "2080")
+	err := SendToast("linux", "2080 Is ready for checkout")
 	if err != nil {
 		t.Errorf("Expected nil error, got %#v", err)
 	}
 
-	err = SendToast("darwin", "2080")
+	err = SendToast("darwin", "2080 Is ready for checkout")
 	if err != nil {
 		t.Errorf("Expected nil error, got %#v", err)
 	}
 }
+
+func TestDarwinToastArguments(t *testing.T) {
+	var args []string
+	execCommand = func(command string, arguments ...string) *exec.Cmd {
+		args = append([]string{command}, arguments...)
+		return fakeExecCommand(command, arguments...)
+	}
+	defer func() { execCommand = exec.Command }()
+
+	message := `RTX 3080 "Founders" \ Edition`
+
+	err := SendToast("darwin", message)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	assert.Equal(t, "osascript", args[0])
+	assert.Equal(t, message, args[len(args)-1], "the message is passed as an argument rather than spliced into the script")
+	for _, arg := range args[1 : len(args)-1] {
+		assert.NotContains(t, arg, "Founders")
+	}
+}
@@ -1,6 +1,7 @@
 package alert
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -9,18 +10,79 @@ import (
 	"github.com/ianmarmour/nvidia-clerk/internal/config"
 )
 
+func init() {
+	Register(Channel{
+		Name:     "sms",
+		Usage:    "Enable SMS notifications for whenever SKU is in stock.",
+		New:      newTwilioNotifier,
+		Settings: config.Section{New: func() interface{} { return &TwilioConfig{} }, Resolve: resolveTwilio},
+	})
+}
+
+// TwilioConfig represents the Twilio account SMS messages are sent from and the number they are sent to.
+type TwilioConfig struct {
+	AccountSID        string `yaml:"account_sid"`
+	Token             string `yaml:"token"`
+	SourceNumber      string `yaml:"source_number"`
+	DestinationNumber string `yaml:"destination_number"`
+}
+
+// resolveTwilio Reads the TWILIO_ environment variables over the values of the configuration file.
+func resolveTwilio(settings interface{}, r *config.Resolver) {
+	c := settings.(*TwilioConfig)
+	r.Required("account_sid", "TWILIO_ACCOUNT_SID", &c.AccountSID)
+	r.Required("token", "TWILIO_TOKEN", &c.Token)
+	r.Required("source_number", "TWILIO_SOURCE_NUMBER", &c.SourceNumber)
+	r.Required("destination_number", "TWILIO_DESTINATION_NUMBER", &c.DestinationNumber)
+}
+
+// twilioNotifier delivers stock events as SMS messages using Twilio Service.
+type twilioNotifier struct {
+	config    TwilioConfig
+	templates Templates
+	client    *http.Client
+}
+
+func newTwilioNotifier(cfg config.Config, client *http.Client) (Notifier, error) {
+	settings, ok := cfg.Notifiers["sms"].(*TwilioConfig)
+	if ok == false {
+		return nil, nil
+	}
+
+	templates, err := NewTemplates(cfg, "sms")
+	if err != nil {
+		return nil, err
+	}
+
+	return &twilioNotifier{*settings, templates, client}, nil
+}
+
+// Name returns the channel name of the notifier.
+func (n *twilioNotifier) Name() string {
+	return "sms"
+}
+
+// Notify sends an SMS for a stock event.
+func (n *twilioNotifier) Notify(ctx context.Context, event StockEvent) error {
+	message, err := n.templates.Render(event.Kind(), event)
+	if err != nil {
+		return err
+	}
+
+	return SendText(ctx, message, n.config, n.client)
+}
+
 //SendText Sends an SMS notification using Twilio Service.
-func SendText(item string, nvidiaURL string, config config.TwilioConfig, client *http.Client) error {
-	str := item + " Ready for Purchase: " + "." + nvidiaURL + "."
+func SendText(ctx context.Context, message string, config TwilioConfig, client *http.Client) error {
 	api := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages", config.AccountSID)
 	data := url.Values{
 		"To":   {config.DestinationNumber},
 		"From": {config.SourceNumber},
-		"Body": {str},
+		"Body": {message},
 	}
 	reader := *strings.NewReader(data.Encode())
 
-	req, err := http.NewRequest("POST", api, &reader)
+	req, err := http.NewRequestWithContext(ctx, "POST", api, &reader)
 	if err != nil {
 		return err
 	}
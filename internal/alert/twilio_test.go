@@ -2,11 +2,10 @@ package alert
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"testing"
-
-	"github.com/ianmarmour/nvidia-clerk/internal/config"
 )
 
 func TestSendText(t *testing.T) {
@@ -26,14 +25,14 @@ func TestSendText(t *testing.T) {
 		}
 	})
 
-	cfg := config.TwilioConfig{
+	cfg := TwilioConfig{
 		AccountSID:        "1",
 		Token:             "fake",
 		SourceNumber:      "fake",
 		DestinationNumber: "fake",
 	}
 
-	err := SendText("FAKE_SKU_NUMBER", "fakeurl", cfg, client)
+	err := SendText(context.Background(), "FAKE_SKU_NUMBER Ready for Purchase: .fakeurl.", cfg, client)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -1,24 +1,111 @@
 package alert
 
 import (
-	"fmt"
+	"context"
+	"net/http"
 
 	"github.com/dghubble/go-twitter/twitter"
 	"github.com/dghubble/oauth1"
 	"github.com/ianmarmour/nvidia-clerk/internal/config"
 )
 
+func init() {
+	Register(Channel{
+		Name:     "twitter",
+		Usage:    "Enable Twitter Posts for whenever SKU is in stock.",
+		New:      newTwitterNotifier,
+		Settings: config.Section{New: func() interface{} { return &TwitterConfig{} }, Resolve: resolveTwitter},
+	})
+}
+
+// TwitterConfig represents the credentials of the Twitter account Tweets are posted from.
+type TwitterConfig struct {
+	ConsumerKey    string `yaml:"consumer_key"`
+	ConsumerSecret string `yaml:"consumer_secret"`
+	AccessToken    string `yaml:"access_token"`
+	AccessSecret   string `yaml:"access_secret"`
+}
+
+// resolveTwitter Reads the TWITTER_ environment variables over the values of the configuration file.
+func resolveTwitter(settings interface{}, r *config.Resolver) {
+	c := settings.(*TwitterConfig)
+	r.Required("consumer_key", "TWITTER_CONSUMER_KEY", &c.ConsumerKey)
+	r.Required("consumer_secret", "TWITTER_CONSUMER_SECRET", &c.ConsumerSecret)
+	r.Required("access_token", "TWITTER_ACCESS_TOKEN", &c.AccessToken)
+	r.Required("access_secret", "TWITTER_ACCESS_SECRET", &c.AccessSecret)
+}
+
+// twitterNotifier delivers stock events as Tweets.
+type twitterNotifier struct {
+	config    TwitterConfig
+	templates Templates
+	client    *http.Client
+}
+
+func newTwitterNotifier(cfg config.Config, client *http.Client) (Notifier, error) {
+	settings, ok := cfg.Notifiers["twitter"].(*TwitterConfig)
+	if ok == false {
+		return nil, nil
+	}
+
+	templates, err := NewTemplates(cfg, "twitter")
+	if err != nil {
+		return nil, err
+	}
+
+	return &twitterNotifier{*settings, templates, client}, nil
+}
+
+// Name returns the channel name of the notifier.
+func (n *twitterNotifier) Name() string {
+	return "twitter"
+}
+
+// Notify posts a Tweet for a stock event.
+func (n *twitterNotifier) Notify(ctx context.Context, event StockEvent) error {
+	message, err := n.templates.Render(event.Kind(), event)
+	if err != nil {
+		return err
+	}
+
+	return SendTweet(ctx, message, n.config, n.client)
+}
+
 //SendTweet Sends an Tweet.
-func SendTweet(item string, nvidiaURL string, config config.TwitterConfig) error {
+func SendTweet(ctx context.Context, message string, config TwitterConfig, client *http.Client) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	// oauth1 only takes the transport of the client it is given, the timeout has to be put back.
+	ctx = context.WithValue(ctx, oauth1.HTTPClient, &http.Client{Transport: &contextTransport{ctx, base}})
+
 	oauth := oauth1.NewConfig(config.ConsumerKey, config.ConsumerSecret)
 	token := oauth1.NewToken(config.AccessToken, config.AccessSecret)
-	http := oauth.Client(oauth1.NoContext, token)
+	http := oauth.Client(ctx, token)
+	http.Timeout = client.Timeout
 	twitter := twitter.NewClient(http)
 
-	_, _, err := twitter.Statuses.Update(fmt.Sprintf("%s Ready for Purchase: %s", item, nvidiaURL), nil)
+	_, _, err := twitter.Statuses.Update(message, nil)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// contextTransport sends every request with ctx so it is cancelled along with it, go-twitter builds its requests without one.
+type contextTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+// RoundTrip sends a request with the context of the transport.
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
@@ -0,0 +1,46 @@
+package alert
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingTransport never answers, returning only once the request is cancelled.
+type blockingTransport struct{}
+
+// RoundTrip .
+func (blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestTwitterNotifyCancelled(t *testing.T) {
+	cfg := config.Config{Notifiers: map[string]interface{}{
+		"twitter": &TwitterConfig{ConsumerKey: "1", ConsumerSecret: "1", AccessToken: "1", AccessSecret: "1"},
+	}}
+
+	notifier, err := newTwitterNotifier(cfg, &http.Client{Transport: blockingTransport{}})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- notifier.Notify(ctx, testEvent())
+	}()
+
+	select {
+	case err := <-done:
+		assert.NotNil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Notify did not return after its context expired")
+	}
+}
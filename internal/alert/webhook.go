@@ -0,0 +1,300 @@
+package alert
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+func init() {
+	Register(Channel{
+		Name:     "webhook",
+		Usage:    "Enable signed JSON webhook notifications to your own URLs for whenever SKU is in stock.",
+		New:      newWebhookNotifier,
+		Settings: config.Section{New: func() interface{} { return &WebhookConfig{} }, Resolve: resolveWebhook},
+	})
+}
+
+// WebhookSignatureHeader carries the hex encoded HMAC-SHA256 of the request body, prefixed with sha256=.
+const WebhookSignatureHeader = "X-Clerk-Signature"
+
+// WebhookEventHeader carries the kind of the event in the payload E.X. in_stock.
+const WebhookEventHeader = "X-Clerk-Event"
+
+// WebhookConfig represents the URLs stock events are POSTed to as signed JSON and how each delivery is retried.
+type WebhookConfig struct {
+	URLs    []string          `yaml:"urls"`
+	Headers map[string]string `yaml:"headers"`
+
+	// Secret signs every payload with HMAC-SHA256 in the X-Clerk-Signature header, empty sends it unsigned.
+	Secret string `yaml:"secret"`
+
+	// Timeout of each attempt, Attempts including the first and RetryDelay before the first retry, doubling after each.
+	Timeout    time.Duration `yaml:"timeout"`
+	Attempts   int           `yaml:"attempts"`
+	RetryDelay time.Duration `yaml:"retry_delay"`
+}
+
+// resolveWebhook Reads WEBHOOK_URLS and WEBHOOK_SECRET over the values of the configuration file, filling in the default timeout and retry policy.
+func resolveWebhook(settings interface{}, r *config.Resolver) {
+	c := settings.(*WebhookConfig)
+
+	r.List("WEBHOOK_URLS", &c.URLs)
+	r.Optional("WEBHOOK_SECRET", &c.Secret)
+
+	if len(c.URLs) == 0 {
+		r.Report("urls is required, set it in the configuration file or with WEBHOOK_URLS", "urls")
+	}
+
+	for i, u := range c.URLs {
+		if config.IsHTTPURL(u) == false {
+			r.Report(fmt.Sprintf("%s: urls must be absolute http or https URLs", u), "urls", i)
+		}
+	}
+
+	if c.Timeout < 0 {
+		r.Report("timeout must not be negative", "timeout")
+	} else if c.Timeout == 0 {
+		c.Timeout = 5 * time.Second
+	}
+
+	if c.Attempts < 0 {
+		r.Report("attempts must not be negative", "attempts")
+	} else if c.Attempts == 0 {
+		c.Attempts = 3
+	}
+
+	if c.RetryDelay < 0 {
+		r.Report("retry_delay must not be negative", "retry_delay")
+	} else if c.RetryDelay == 0 {
+		c.RetryDelay = time.Second
+	}
+}
+
+// webhookNotifier delivers stock events and API status changes as JSON POSTed to every configured URL.
+type webhookNotifier struct {
+	config    WebhookConfig
+	templates Templates
+	client    *http.Client
+}
+
+func newWebhookNotifier(cfg config.Config, client *http.Client) (Notifier, error) {
+	settings, ok := cfg.Notifiers["webhook"].(*WebhookConfig)
+	if ok == false {
+		return nil, nil
+	}
+
+	templates, err := NewTemplates(cfg, "webhook")
+	if err != nil {
+		return nil, err
+	}
+
+	return &webhookNotifier{*settings, templates, client}, nil
+}
+
+// Name returns the channel name of the notifier.
+func (n *webhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Deadline Gets the longest delivery can take, every attempt timing out with the backoff between them.
+func (n *webhookNotifier) Deadline() time.Duration {
+	return WebhookDeadline(n.config)
+}
+
+// Notify POSTs a payload describing a stock event to every webhook URL.
+func (n *webhookNotifier) Notify(ctx context.Context, event StockEvent) error {
+	message, err := n.templates.Render(event.Kind(), event)
+	if err != nil {
+		return err
+	}
+
+	return SendWebhook(ctx, NewStockPayload(event, message), n.config, n.client)
+}
+
+// NotifyAPI POSTs a payload describing a change in the status of one of NVIDIAs APIs to every webhook URL.
+func (n *webhookNotifier) NotifyAPI(ctx context.Context, event APIEvent) error {
+	message, err := n.templates.Render(event.Kind(), event)
+	if err != nil {
+		return err
+	}
+
+	return SendWebhook(ctx, NewAPIPayload(event, message, time.Now()), n.config, n.client)
+}
+
+// WebhookPayload represents the JSON body POSTed to webhook URLs, fields that don't apply to an event kind are omitted.
+type WebhookPayload struct {
+	// Kind one of in_stock, out_of_stock, price_drop, price_increase, api_online or api_offline.
+	Kind string `json:"kind"`
+
+	// Message rendered from the webhook template for Kind.
+	Message string `json:"message"`
+
+	Model          string `json:"model,omitempty"`
+	Region         string `json:"region,omitempty"`
+	SKU            string `json:"sku,omitempty"`
+	Name           string `json:"name,omitempty"`
+	DisplayName    string `json:"display_name,omitempty"`
+	Price          string `json:"price,omitempty"`
+	PreviousPrice  string `json:"previous_price,omitempty"`
+	Thumbnail      string `json:"thumbnail,omitempty"`
+	Status         string `json:"status,omitempty"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+	CartURL        string `json:"cart_url,omitempty"`
+
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewStockPayload Generates a WebhookPayload describing a StockEvent with its rendered message.
+func NewStockPayload(event StockEvent, message string) WebhookPayload {
+	return WebhookPayload{
+		Kind:           event.Kind(),
+		Message:        message,
+		Model:          event.Model,
+		Region:         event.Region,
+		SKU:            event.SKU,
+		Name:           event.Name,
+		DisplayName:    event.DisplayName,
+		Price:          event.Price,
+		PreviousPrice:  event.PreviousPrice,
+		Thumbnail:      event.Thumbnail,
+		Status:         event.Status,
+		PreviousStatus: event.PreviousStatus,
+		CartURL:        event.CartURL,
+		Timestamp:      event.Timestamp.UTC(),
+	}
+}
+
+// NewAPIPayload Generates a WebhookPayload describing an APIEvent observed at t with its rendered message.
+func NewAPIPayload(event APIEvent, message string, t time.Time) WebhookPayload {
+	return WebhookPayload{
+		Kind:      event.Kind(),
+		Message:   message,
+		Name:      event.Name,
+		Status:    event.Status,
+		Timestamp: t.UTC(),
+	}
+}
+
+// SignWebhook Generates the value of the signature header for a body, sha256= followed by its hex encoded HMAC-SHA256.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SendWebhook POSTs a payload to every URL in cfg at once, retrying each independently and reporting every URL that failed.
+func SendWebhook(ctx context.Context, payload WebhookPayload, cfg WebhookConfig, client *http.Client) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	for key, value := range cfg.Headers {
+		header.Set(key, value)
+	}
+	header.Set(WebhookEventHeader, payload.Kind)
+	if cfg.Secret != "" {
+		header.Set(WebhookSignatureHeader, SignWebhook(cfg.Secret, body))
+	}
+
+	errs := make([]error, len(cfg.URLs))
+
+	var wg sync.WaitGroup
+	for i, url := range cfg.URLs {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			errs[i] = postWebhook(ctx, client, url, body, header, cfg)
+		}(i, url)
+	}
+	wg.Wait()
+
+	failures := []string{}
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", cfg.URLs[i], err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// WebhookDeadline Gets the longest delivering to every URL in cfg can take, URLs are tried at the same time.
+func WebhookDeadline(cfg WebhookConfig) time.Duration {
+	deadline := time.Duration(0)
+	delay := cfg.RetryDelay
+
+	for attempt := 1; attempt <= cfg.Attempts; attempt++ {
+		deadline += cfg.Timeout
+		if attempt < cfg.Attempts {
+			deadline += delay
+			delay *= 2
+		}
+	}
+
+	return deadline
+}
+
+// postWebhook POSTs a body to a single URL, retrying network errors, timeouts, rate limiting and server errors.
+//
+// A Retry-After that would run past the deadline of ctx ends the retries straight away with the last error.
+func postWebhook(ctx context.Context, client *http.Client, url string, body []byte, header http.Header, cfg WebhookConfig) error {
+	delay := cfg.RetryDelay
+
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+		err := postJSON(attemptCtx, client, url, body, header)
+		cancel()
+
+		if err == nil || attempt >= cfg.Attempts || ctx.Err() != nil || retryable(err) == false {
+			return err
+		}
+
+		wait := delay
+		var httpErr *rest.HTTPError
+		if errors.As(err, &httpErr) {
+			if after, ok := httpErr.RetryAfter(time.Now()); ok {
+				wait = after
+			}
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return err
+		}
+
+		sleepErr := rest.Sleep(ctx, wait)
+		if sleepErr != nil {
+			return sleepErr
+		}
+
+		delay *= 2
+	}
+}
+
+// retryable Determines if a failed delivery may succeed when repeated, other client errors never will.
+func retryable(err error) bool {
+	var httpErr *rest.HTTPError
+	if errors.As(err, &httpErr) == false {
+		return true
+	}
+
+	return errors.Is(err, rest.ErrRateLimited) || errors.Is(err, rest.ErrServerError)
+}
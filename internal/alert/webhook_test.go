@@ -0,0 +1,244 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+func testWebhookConfig(urls ...string) WebhookConfig {
+	return WebhookConfig{URLs: urls, Timeout: time.Second, Attempts: 3, RetryDelay: time.Millisecond}
+}
+
+func webhookResponse(code int) *http.Response {
+	return &http.Response{
+		StatusCode: code,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`OK`)),
+		Header:     make(http.Header),
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var body []byte
+	var header http.Header
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		body, _ = ioutil.ReadAll(req.Body)
+		header = req.Header
+		return webhookResponse(204)
+	})
+
+	cfg := testWebhookConfig("https://example.com/hooks/clerk")
+	cfg.Secret = "shh"
+	cfg.Headers = map[string]string{"Authorization": "Bearer token"}
+
+	notifier, err := newWebhookNotifier(config.Config{Notifiers: map[string]interface{}{"webhook": &cfg}}, client)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = notifier.Notify(context.Background(), testEvent())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+	assert.Equal(t, "in_stock", header.Get(WebhookEventHeader))
+	assert.Equal(t, SignWebhook("shh", body), header.Get(WebhookSignatureHeader))
+
+	payload := map[string]interface{}{}
+	json.Unmarshal(body, &payload)
+
+	assert.Equal(t, map[string]interface{}{
+		"kind":            "in_stock",
+		"message":         "NVIDIA GEFORCE RTX 3080 (DEU, 699,00 €) Ready for Purchase: https://store.nvidia.com/cart",
+		"model":           "3080",
+		"region":          "DEU",
+		"sku":             "5440853700",
+		"name":            "NVIDIA GEFORCE RTX 3080",
+		"display_name":    "NVIDIA GEFORCE RTX 3080 ",
+		"price":           "699,00 €",
+		"thumbnail":       "https://assets.nvidia.partners/images/png/nvidia-geforce-rtx-3080.png",
+		"status":          "PRODUCT_INVENTORY_IN_STOCK",
+		"previous_status": "PRODUCT_INVENTORY_OUT_OF_STOCK",
+		"cart_url":        "https://store.nvidia.com/cart",
+		"timestamp":       "2020-09-17T13:00:00Z",
+	}, payload)
+}
+
+func TestSignWebhook(t *testing.T) {
+	// Reference value from: echo -n '{"kind":"in_stock"}' | openssl dgst -sha256 -hmac shh
+	assert.Equal(t, "sha256=efce6bc65422c4a1f8ad1b07c049b0f05e9f8e30fb0ff2568e627f31c6d8c0b1", SignWebhook("shh", []byte(`{"kind":"in_stock"}`)))
+}
+
+func TestSendWebhookUnsigned(t *testing.T) {
+	var header http.Header
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		header = req.Header
+		return webhookResponse(200)
+	})
+
+	err := SendWebhook(context.Background(), NewAPIPayload(APIEvent{Name: "Store Session", Status: "offline"}, "offline", time.Now()), testWebhookConfig("https://example.com/hook"), client)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	assert.Equal(t, "", header.Get(WebhookSignatureHeader))
+	assert.Equal(t, "api_offline", header.Get(WebhookEventHeader))
+}
+
+func TestSendWebhookRetries(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		mu.Lock()
+		defer mu.Unlock()
+
+		url := req.URL.String()
+		attempts[url]++
+
+		switch url {
+		case "https://example.com/flaky":
+			if attempts[url] < 3 {
+				return webhookResponse(503)
+			}
+			return webhookResponse(200)
+		case "https://example.com/limited":
+			response := webhookResponse(429)
+			response.Header.Set("Retry-After", "0")
+			return response
+		default:
+			return webhookResponse(404)
+		}
+	})
+
+	cfg := testWebhookConfig("https://example.com/flaky", "https://example.com/limited", "https://example.com/missing")
+
+	err := SendWebhook(context.Background(), NewStockPayload(testEvent(), "test"), cfg, client)
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "https://example.com/flaky")
+	assert.Contains(t, err.Error(), "https://example.com/limited")
+	assert.Contains(t, err.Error(), "https://example.com/missing")
+
+	assert.Equal(t, 3, attempts["https://example.com/flaky"], "server errors are retried")
+	assert.Equal(t, 3, attempts["https://example.com/limited"], "rate limiting is retried until attempts run out")
+	assert.Equal(t, 1, attempts["https://example.com/missing"], "client errors aren't retried")
+}
+
+func TestSendWebhookDeadline(t *testing.T) {
+	calls := 0
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		calls++
+		return webhookResponse(500)
+	})
+
+	cfg := testWebhookConfig("https://example.com/hook")
+	cfg.RetryDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// A retry that can't happen before the deadline isn't waited for.
+	started := time.Now()
+	err := postWebhook(ctx, client, "https://example.com/hook", []byte(`{}`), http.Header{}, cfg)
+	assert.True(t, errors.Is(err, rest.ErrServerError))
+	assert.Equal(t, 1, calls)
+	assert.True(t, time.Since(started) < 50*time.Millisecond)
+}
+
+func TestWebhookDeadline(t *testing.T) {
+	cfg := WebhookConfig{Timeout: 5 * time.Second, Attempts: 3, RetryDelay: time.Second}
+	assert.Equal(t, 18*time.Second, WebhookDeadline(cfg), "three 5s attempts with 1s and 2s between them")
+
+	cfg.Attempts = 1
+	assert.Equal(t, 5*time.Second, WebhookDeadline(cfg))
+
+	notifier, _ := newWebhookNotifier(config.Config{Notifiers: map[string]interface{}{"webhook": &cfg}}, nil)
+	assert.Equal(t, 5*time.Second, notifier.(DeadlineNotifier).Deadline())
+}
+
+func TestRetryable(t *testing.T) {
+	assert.True(t, retryable(errors.New("connection refused")))
+	assert.True(t, retryable(&rest.HTTPError{StatusCode: 502}))
+	assert.True(t, retryable(&rest.HTTPError{StatusCode: 429}))
+	assert.False(t, retryable(&rest.HTTPError{StatusCode: 401}))
+}
+
+func TestWebhookSettings(t *testing.T) {
+	defer os.Unsetenv("WEBHOOK_URLS")
+	defer os.Unsetenv("WEBHOOK_SECRET")
+	os.Setenv("WEBHOOK_SECRET", "shh")
+
+	file := readTestFile(t, `update: false
+watches:
+  - regions: [USA]
+    models: [3080]
+notifiers:
+  webhook:
+    urls: [https://example.com/hooks/clerk]
+    headers:
+      Authorization: Bearer token
+    attempts: 1
+`)
+
+	result, err := file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := &WebhookConfig{
+		URLs:       []string{"https://example.com/hooks/clerk"},
+		Headers:    map[string]string{"Authorization": "Bearer token"},
+		Secret:     "shh",
+		Timeout:    5 * time.Second,
+		Attempts:   1,
+		RetryDelay: time.Second,
+	}
+	assert.Equal(t, expected, result.Notifiers["webhook"])
+
+	os.Setenv("WEBHOOK_URLS", "https://a.example.com, https://b.example.com")
+
+	result, err = file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, result.Notifiers["webhook"].(*WebhookConfig).URLs)
+}
+
+func TestWebhookSettingsValidation(t *testing.T) {
+	file := readTestFile(t, `update: false
+watches:
+  - regions: [USA]
+    models: [3080]
+notifiers:
+  webhook:
+    urls: [https://example.com, example.com/hook]
+    timeout: -1s
+    attempts: -1
+`)
+
+	_, err := file.Config()
+	errs := fileErrors(t, err)
+
+	assert.Equal(t, []string{
+		"7: example.com/hook: urls must be absolute http or https URLs",
+		"8: timeout must not be negative",
+		"9: attempts must not be negative",
+	}, errs)
+
+	assert.Nil(t, file.Set("webhook", "false"))
+	assert.Nil(t, file.Set("webhook", "true"))
+	_, err = file.Config()
+	assert.Equal(t, "-webhook: urls is required, set it in the configuration file or with WEBHOOK_URLS", err.Error())
+}
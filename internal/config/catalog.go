@@ -0,0 +1,218 @@
+package config
+
+import (
+	"bytes"
+	_ "embed" // Required for embedding the default catalog.
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Catalog represents every supported region and its models keyed by 3 letter region code.
+type Catalog map[string]RegionalConfig
+
+//go:embed catalog.json
+var defaultCatalog []byte
+
+// DefaultProductURL Product page template used for models without a productUrl.
+const DefaultProductURL = "https://www.nvidia.com/{nvidiaLocale}/geforce/"
+
+// RegionalConfigs SKU to locale/currency mappings to avoid user pain of having to lookup and enter these.
+var RegionalConfigs = mustParseCatalog(defaultCatalog)
+
+var (
+	regionRegexp       = regexp.MustCompile(`^[A-Z]{3}$`)
+	localeRegexp       = regexp.MustCompile(`^[a-z]{2}_[a-z]{2}$`)
+	nvidiaLocaleRegexp = regexp.MustCompile(`^[a-z]{2}-[a-z]{2}$`)
+	currencyRegexp     = regexp.MustCompile(`^[A-Z]{3}$`)
+	skuRegexp          = regexp.MustCompile(`^[0-9]+$`)
+)
+
+func mustParseCatalog(data []byte) Catalog {
+	c, err := ParseCatalog("catalog.json", data)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// ParseCatalog Decodes and validates a JSON catalog document.
+func ParseCatalog(source string, data []byte) (Catalog, error) {
+	c := Catalog{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(&c)
+	if err != nil {
+		return nil, FileErrors{&FileError{File: source, Message: err.Error()}}
+	}
+
+	err = c.Validate(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// LoadCatalog Loads a catalog from a local file or a http(s) URL.
+func LoadCatalog(source string, client *http.Client) (Catalog, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		r, err := client.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Body.Close()
+
+		if r.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: unexpected status %s", source, r.Status)
+		}
+
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		return ParseCatalog(source, data)
+	}
+
+	data, err := ioutil.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseCatalog(source, data)
+}
+
+// SetCatalog Replaces the supported regions and models, logging every difference from the previous catalog.
+func SetCatalog(c Catalog) {
+	for _, change := range RegionalConfigs.Diff(c) {
+		log.Println(fmt.Sprintf("Catalog: %s", change))
+	}
+
+	RegionalConfigs = c
+}
+
+// Validate Checks that every region in the catalog is complete and well formed.
+func (c Catalog) Validate(source string) error {
+	errs := FileErrors{}
+	invalid := func(format string, a ...interface{}) {
+		errs = append(errs, &FileError{File: source, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if len(c) == 0 {
+		invalid("catalog contains no regions")
+	}
+
+	for _, region := range c.regions() {
+		rc := c[region]
+
+		if regionRegexp.MatchString(region) == false {
+			invalid("%s: region must be a 3 letter uppercase code", region)
+		}
+
+		if localeRegexp.MatchString(rc.Locale) == false {
+			invalid("%s.locale: %q must look like en_us", region, rc.Locale)
+		}
+
+		if nvidiaLocaleRegexp.MatchString(rc.NvidiaLocale) == false {
+			invalid("%s.nvidiaLocale: %q must look like en-us", region, rc.NvidiaLocale)
+		}
+
+		if currencyRegexp.MatchString(rc.Currency) == false {
+			invalid("%s.currency: %q must be a 3 letter uppercase code", region, rc.Currency)
+		}
+
+		if len(rc.Models) == 0 {
+			invalid("%s.models: region contains no models", region)
+		}
+
+		for _, model := range getSupportedModels(rc) {
+			sku := rc.Models[model].SKU
+			if sku == nil || skuRegexp.MatchString(*sku) == false {
+				invalid("%s.models.%s.sku: must be numeric", region, model)
+			}
+
+			productURL := rc.Models[model].ProductURL
+			if productURL != "" && IsHTTPURL(RenderProductURL(productURL, rc.NvidiaLocale)) == false {
+				invalid("%s.models.%s.productUrl: %q must be an absolute http or https URL", region, model, productURL)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Diff Describes every region, model and SKU change required to turn this catalog into next.
+func (c Catalog) Diff(next Catalog) []string {
+	changes := []string{}
+
+	for _, region := range c.regions() {
+		if _, ok := next[region]; ok == false {
+			changes = append(changes, fmt.Sprintf("removed region %s", region))
+		}
+	}
+
+	for _, region := range next.regions() {
+		nc := next[region]
+
+		pc, ok := c[region]
+		if ok == false {
+			changes = append(changes, fmt.Sprintf("added region %s with models %v", region, getSupportedModels(nc)))
+			continue
+		}
+
+		if pc.Locale != nc.Locale || pc.NvidiaLocale != nc.NvidiaLocale || pc.Currency != nc.Currency {
+			changes = append(changes, fmt.Sprintf("%s locale changed from %s/%s/%s to %s/%s/%s", region, pc.Locale, pc.NvidiaLocale, pc.Currency, nc.Locale, nc.NvidiaLocale, nc.Currency))
+		}
+
+		for _, model := range getSupportedModels(pc) {
+			if _, ok := nc.Models[model]; ok == false {
+				changes = append(changes, fmt.Sprintf("%s removed model %s", region, model))
+			}
+		}
+
+		for _, model := range getSupportedModels(nc) {
+			nm := nc.Models[model]
+
+			pm, ok := pc.Models[model]
+			if ok == false {
+				changes = append(changes, fmt.Sprintf("%s added model %s with SKU %s", region, model, *nm.SKU))
+				continue
+			}
+
+			if *pm.SKU != *nm.SKU {
+				changes = append(changes, fmt.Sprintf("%s model %s SKU changed from %s to %s", region, model, *pm.SKU, *nm.SKU))
+			}
+
+			if pm.ProductURL != nm.ProductURL {
+				changes = append(changes, fmt.Sprintf("%s model %s product page changed from %q to %q", region, model, pm.ProductURL, nm.ProductURL))
+			}
+		}
+	}
+
+	return changes
+}
+
+// regions Gets a sorted list of every region in the catalog.
+func (c Catalog) regions() []string {
+	keys := []string{}
+
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testCatalog = `{
+  "USA": {
+    "locale": "en_us",
+    "nvidiaLocale": "en-us",
+    "currency": "USD",
+    "models": {
+      "3070": {"sku": "5438481900"},
+      "3080": {"sku": "5438481701"}
+    }
+  }
+}`
+
+func TestDefaultCatalog(t *testing.T) {
+	assert.Nil(t, RegionalConfigs.Validate("catalog.json"))
+	assert.Equal(t, 19, len(RegionalConfigs))
+	assert.Equal(t, "5438481700", *RegionalConfigs["USA"].Models["3080"].SKU)
+	assert.Equal(t, "https://www.nvidia.com/{nvidiaLocale}/geforce/graphics-cards/rtx-2080-ti/", RegionalConfigs["USA"].Models["2080TI"].ProductURL)
+}
+
+func TestRenderProductURL(t *testing.T) {
+	assert.Equal(t, "https://www.nvidia.com/de-de/geforce/graphics-cards/30-series/rtx-3070/", RenderProductURL("https://www.nvidia.com/{nvidiaLocale}/geforce/graphics-cards/30-series/rtx-3070/", "de-de"))
+	assert.Equal(t, "https://www.nvidia.com/en-gb/geforce/", RenderProductURL("", "en-gb"))
+}
+
+func TestParseCatalogProductURL(t *testing.T) {
+	_, err := ParseCatalog("catalog.json", []byte(`{
+  "USA": {
+    "locale": "en_us",
+    "nvidiaLocale": "en-us",
+    "currency": "USD",
+    "models": {"3070": {"sku": "5438481900", "productUrl": "/{nvidiaLocale}/geforce/rtx-3070/"}}
+  }
+}`))
+	assert.Equal(t, `catalog.json: USA.models.3070.productUrl: "/{nvidiaLocale}/geforce/rtx-3070/" must be an absolute http or https URL`, err.Error())
+}
+
+func TestParseCatalogValidation(t *testing.T) {
+	data := []byte(`{
+  "usa": {
+    "locale": "en-us",
+    "nvidiaLocale": "en-us",
+    "currency": "USD",
+    "models": {"3080": {"sku": "abc"}}
+  },
+  "CAN": {"locale": "en_us", "nvidiaLocale": "en-us", "currency": "CAD", "models": {}}
+}`)
+
+	_, err := ParseCatalog("catalog.json", data)
+	errs, ok := err.(FileErrors)
+	if ok == false {
+		t.Fatalf("Expected FileErrors, got %#v", err)
+	}
+
+	assert.Equal(t, []string{
+		"catalog.json: CAN.models: region contains no models",
+		"catalog.json: usa: region must be a 3 letter uppercase code",
+		"catalog.json: usa.locale: \"en-us\" must look like en_us",
+		"catalog.json: usa.models.3080.sku: must be numeric",
+	}, []string{errs[0].Error(), errs[1].Error(), errs[2].Error(), errs[3].Error()})
+}
+
+func TestParseCatalogUnknownField(t *testing.T) {
+	_, err := ParseCatalog("catalog.json", []byte(`{"USA": {"region": "USA"}}`))
+	assert.NotNil(t, err)
+}
+
+func TestCatalogDiff(t *testing.T) {
+	next, err := ParseCatalog("test", []byte(testCatalog))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	previous := Catalog{"USA": RegionalConfigs["USA"], "CAN": RegionalConfigs["CAN"]}
+
+	assert.Equal(t, []string{
+		"removed region CAN",
+		"USA removed model 2060",
+		"USA removed model 2070",
+		"USA removed model 2080",
+		"USA removed model 2080TI",
+		"USA removed model 3090",
+		"USA added model 3070 with SKU 5438481900",
+		"USA model 3080 SKU changed from 5438481700 to 5438481701",
+		"USA model 3080 product page changed from \"https://www.nvidia.com/{nvidiaLocale}/geforce/graphics-cards/30-series/rtx-3080/\" to \"\"",
+	}, previous.Diff(next))
+}
+
+func TestLoadCatalog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testCatalog)
+	}))
+	defer server.Close()
+
+	fromURL, err := LoadCatalog(server.URL, server.Client())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, "5438481900", *fromURL["USA"].Models["3070"].SKU)
+
+	dir, err := ioutil.TempDir("", "catalog")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "catalog.json")
+	ioutil.WriteFile(path, []byte(testCatalog), 0644)
+
+	fromFile, err := LoadCatalog(path, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, fromURL, fromFile)
+}
+
+func TestSetCatalog(t *testing.T) {
+	previous := RegionalConfigs
+	defer func() { RegionalConfigs = previous }()
+
+	next, _ := ParseCatalog("test", []byte(testCatalog))
+	SetCatalog(next)
+
+	assert.Equal(t, []string{"USA"}, getSupportedRegions())
+	assert.Equal(t, []string{"3070", "3080"}, getSupportedModels(RegionalConfigs["USA"]))
+}
@@ -3,8 +3,13 @@ package config
 import (
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type RegionError struct {
@@ -33,65 +38,104 @@ func (w *ConfigError) Error() string {
 }
 
 type Model struct {
-	SKU *string
-}
+	SKU         *string `json:"sku"`
+	DisplayName string  `json:"displayName,omitempty"`
+	Price       string  `json:"price,omitempty"`
 
-type ToastConfig struct {
-	OS string
+	// ProductURL product page of the model, {nvidiaLocale} is replaced by the NvidiaLocale of each region.
+	ProductURL string `json:"productUrl,omitempty"`
 }
 
 type RegionalConfig struct {
-	Models       map[string]Model
-	Locale       string
-	NvidiaLocale string
-	Currency     string
+	Models       map[string]Model `json:"models"`
+	Locale       string           `json:"locale"`
+	NvidiaLocale string           `json:"nvidiaLocale"`
+	Currency     string           `json:"currency"`
 }
 
-type TwitterConfig struct {
-	ConsumerKey    string
-	ConsumerSecret string
-	AccessToken    string
-	AccessSecret   string
+type ShieldsConfig struct {
+	Port string `yaml:"port"`
 }
 
-type TwilioConfig struct {
-	AccountSID        string
-	Token             string
-	SourceNumber      string
-	DestinationNumber string
+// APIConfig represents the endpoints of NVIDIAs store and product APIs, empty values use NVIDIAs production APIs.
+type APIConfig struct {
+	StoreURL  string `yaml:"store_url"`
+	APIURL    string `yaml:"api_url"`
+	UserAgent string `yaml:"user_agent"`
 }
 
-type DiscordConfig struct {
-	WebhookURL string
+type SystemConfig struct {
+	UpdateURL string
 }
 
-type TelegramConfig struct {
-	APIKey string
-	ChatID string
-}
+// Watch represents a single region and model pair being monitored.
+type Watch struct {
+	Region       string
+	Model        string
+	SKU          string
+	Locale       string
+	NvidiaLocale string
+	Currency     string
+	ProductURL   string
 
-type ShieldsConfig struct {
-	Port string
+	// MaxPrice highest sale price worth alerting on in the minor unit of Currency E.X. cents, zero disables it.
+	MaxPrice int64
+
+	// Schedule how often to poll through the week, nil polls every Config.Delay.
+	Schedule *Schedule
 }
 
-type SystemConfig struct {
-	UpdateURL string
+// String returns a short human readable identifier for a Watch.
+func (w Watch) String() string {
+	return fmt.Sprintf("%s/%s", w.Region, w.Model)
 }
 
+// DefaultJitter Most random time added to every delay between polls when none is configured.
+const DefaultJitter = 5 * time.Second
+
 type Config struct {
-	Locale       string
-	NvidiaLocale string
-	Currency     string
-	Delay        int64
-
-	SKU            *string
-	TwilioConfig   *TwilioConfig
-	TwitterConfig  *TwitterConfig
-	DiscordConfig  *DiscordConfig
-	TelegramConfig *TelegramConfig
-	ToastConfig    *ToastConfig
-	ShieldsConfig  *ShieldsConfig
-	SystemConfig   *SystemConfig
+	Delay   time.Duration
+	Remote  bool
+	Watches []Watch
+
+	// Jitter most random time added to every delay between polls to avoid looking like a bot, zero disables it.
+	Jitter time.Duration
+
+	// RateLimit requests per second allowed to each NVIDIA API host across every watch, zero disables it.
+	RateLimit float64
+
+	// Transitions inventory state transitions that trigger alerts E.X. out_of_stock->in_stock.
+	Transitions []string
+
+	// Cooldown suppresses repeated alerts for the same SKU, zero disables it.
+	Cooldown time.Duration
+
+	// PriceChanges alerts when the sale price of a watched product drops or increases.
+	PriceChanges bool
+
+	// APIConfig endpoints of NVIDIAs APIs E.X. a local stand-in server or staging proxy.
+	APIConfig APIConfig
+
+	// History path of a file recording every poll and notification, empty disables it.
+	History string
+
+	// Notifiers settings of every enabled notification channel keyed by name, each created by its registered Section.
+	Notifiers map[string]interface{}
+
+	ShieldsConfig *ShieldsConfig
+	SystemConfig  *SystemConfig
+
+	// Templates message templates keyed by notification channel then event kind.
+	Templates map[string]map[string]string
+}
+
+// setNotifier Enables a notification channel with its settings.
+func (c *Config) setNotifier(name string, settings interface{}) {
+	if c.Notifiers == nil {
+		c.Notifiers = map[string]interface{}{}
+	}
+
+	c.Notifiers[name] = settings
 }
 
 var SystemConfigs = map[string]map[string]SystemConfig{
@@ -121,589 +165,52 @@ var SystemConfigs = map[string]map[string]SystemConfig{
 	},
 }
 
-// Hardcoded SKU to locale/currency mappings to avoid user pain of having to lookup and enter these.
-var RegionalConfigs = map[string]RegionalConfig{
-	"AUT": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394902900"),
-			},
-			"2070": {
-				SKU: strPtr("5394901600"),
-			},
-			"2080": {
-				SKU: strPtr("5335703700"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218984600"),
-			},
-			"3080": {
-				SKU: strPtr("5440853700"),
-			},
-			"3090": {
-				SKU: strPtr("5444941400"),
-			},
-		},
-		Locale:       "de_de",
-		NvidiaLocale: "de-de",
-		Currency:     "EUR",
-	},
-	"BEL": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394902700"),
-			},
-			"2070": {
-				SKU: strPtr("5336534300"),
-			},
-			"2080": {
-				SKU: strPtr("5336531500"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218987100"),
-			},
-			"3080": {
-				SKU: strPtr("5438795700"),
-			},
-			"3090": {
-				SKU: strPtr("5438795600"),
-			},
-		},
-		Locale:       "fr_fr",
-		NvidiaLocale: "fr-fr",
-		Currency:     "EUR",
-	},
-	"CAN": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5379432500"),
-			},
-			"2070": {
-				SKU: strPtr("5379432400"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218984100"),
-			},
-			"3080": {
-				SKU: strPtr("5438481700"),
-			},
-			"3090": {
-				SKU: strPtr("5438481600"),
-			},
-		},
-		Locale:       "en_us",
-		NvidiaLocale: "en-us",
-		Currency:     "CAD",
-	},
-	"CZE": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394902800"),
-			},
-			"2070": {
-				SKU: strPtr("5394901500"),
-			},
-			"2080": {
-				SKU: strPtr("5336531900"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218613300"),
-			},
-			"3080": {
-				SKU: strPtr("5438793800"),
-			},
-			"3090": {
-				SKU: strPtr("5438793600"),
-			},
-		},
-		Locale:       "en_gb",
-		NvidiaLocale: "en-gb",
-		Currency:     "CZK",
-	},
-	"DNK": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394903100"),
-			},
-			"2070": {
-				SKU: strPtr("5394901800"),
-			},
-			"2080": {
-				SKU: strPtr("5336531800"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218988600"),
-			},
-			"3080": {
-				SKU: strPtr("5438793300"),
-			},
-			"3090": {
-				SKU: strPtr("5438793200"),
-			},
-		},
-		Locale:       "en_gb",
-		NvidiaLocale: "en-gb",
-		Currency:     "DKK",
-	},
-	"FIN": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394903100"),
-			},
-			"2070": {
-				SKU: strPtr("5394901800"),
-			},
-			"2080": {
-				SKU: strPtr("5336531800"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218988600"),
-			},
-			"3080": {
-				SKU: strPtr("5438793300"),
-			},
-			"3090": {
-				SKU: strPtr("5438793500"),
-			},
-		},
-		Locale:       "en_gb",
-		NvidiaLocale: "en-gb",
-		Currency:     "EUR",
-	},
-	"FRA": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394903200"),
-			},
-			"2070": {
-				SKU: strPtr("5394901900"),
-			},
-			"2080": {
-				SKU: strPtr("5336531100"),
-			},
-			"3080": {
-				SKU: strPtr("5438795200"),
-			},
-			"3090": {
-				SKU: strPtr("5438761500"),
-			},
-		},
-		Locale:       "fr_fr",
-		NvidiaLocale: "fr-fr",
-		Currency:     "EUR",
-	},
-	"DEU": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394902900"),
-			},
-			"2070": {
-				SKU: strPtr("5394901600"),
-			},
-			"2080": {
-				SKU: strPtr("5335703700"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218984600"),
-			},
-			"3080": {
-				SKU: strPtr("5438792300"),
-			},
-			"3090": {
-				SKU: strPtr("5438761400"),
-			},
-		},
-		Locale:       "de_de",
-		NvidiaLocale: "de-de",
-		Currency:     "EUR",
-	},
-	"USA": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5379432500"),
-			},
-			"2070": {
-				SKU: strPtr("5379432400"),
-			},
-			"2080": {
-				SKU: strPtr("5334463900"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218984100"),
-			},
-			"3080": {
-				SKU: strPtr("5438481700"),
-			},
-			"3090": {
-				SKU: strPtr("5438481600"),
-			},
-		},
-		Locale:       "en_us",
-		NvidiaLocale: "en-us",
-		Currency:     "USD",
-	},
-	"GBR": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394903300"),
-			},
-			"2070": {
-				SKU: strPtr("5394902000"),
-			},
-			"2080": {
-				SKU: strPtr("5336531200"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218985600"),
-			},
-			"3080": {
-				SKU: strPtr("5438792800"),
-			},
-			"3090": {
-				SKU: strPtr("5438792700"),
-			},
-		},
-		Locale:       "en_gb",
-		NvidiaLocale: "en-gb",
-		Currency:     "GBP",
-	},
-	"IRL": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394903300"),
-			},
-			"2070": {
-				SKU: strPtr("5394902000"),
-			},
-			"2080": {
-				SKU: strPtr("5336531200"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218985600"),
-			},
-			"3080": {
-				SKU: strPtr("5438792800"),
-			},
-			"3090": {
-				SKU: strPtr("5438792700"),
-			},
-		},
-		Locale:       "en_gb",
-		NvidiaLocale: "en-gb",
-		Currency:     "GBP",
-	},
-	"ITA": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394903400"),
-			},
-			"2070": {
-				SKU: strPtr("5394902100"),
-			},
-			"2080": {
-				SKU: strPtr("5336532000"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218613900"),
-			},
-			"3080": {
-				SKU: strPtr("5438796200"),
-			},
-			"3090": {
-				SKU: strPtr("5438796100"),
-			},
-		},
-		Locale:       "it_it",
-		NvidiaLocale: "it-it",
-		Currency:     "EUR",
-	},
-	"SWE": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394903900"),
-			},
-			"2070": {
-				SKU: strPtr("5394902500"),
-			},
-			"2080": {
-				SKU: strPtr("5336531300"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218986100"),
-			},
-			"3080": {
-				SKU: strPtr("5438798100"),
-			},
-			"3090": {
-				SKU: strPtr("5438761600"),
-			},
-		},
-		Locale:       "sv_se",
-		NvidiaLocale: "sv-se",
-		Currency:     "SEK",
-	},
-	"LUX": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394902700"),
-			},
-			"2070": {
-				SKU: strPtr("5336534300"),
-			},
-			"2080": {
-				SKU: strPtr("5336531500"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218987100"),
-			},
-			"3080": {
-				SKU: strPtr("5438795700"),
-			},
-			"3090": {
-				SKU: strPtr("5438795600"),
-			},
-		},
-		Locale:       "fr_fr",
-		NvidiaLocale: "fr-fr",
-		Currency:     "EUR",
-	},
-	"POL": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394903700"),
-			},
-			"2070": {
-				SKU: strPtr("5394902300"),
-			},
-			"2080": {
-				SKU: strPtr("5336531600"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218987600"),
-			},
-			"3080": {
-				SKU: strPtr("5438797700"),
-			},
-			"3090": {
-				SKU: strPtr("5438797600"),
-			},
-		},
-		Locale:       "pl_pl",
-		NvidiaLocale: "pl-pl",
-		Currency:     "PLN",
-	},
-	"PRT": {
-		Models: map[string]Model{
-			"3080": {
-				SKU: strPtr("5438794300"),
-			},
-		},
-		Locale:       "en_gb",
-		NvidiaLocale: "en-gb",
-		Currency:     "EUR",
-	},
-	"ESP": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394903000"),
-			},
-			"2070": {
-				SKU: strPtr("5394901700"),
-			},
-			"2080": {
-				SKU: strPtr("5336531400"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218986600"),
-			},
-			"3080": {
-				SKU: strPtr("5438794800"),
-			},
-			"3090": {
-				SKU: strPtr("5438794700"),
-			},
-		},
-		Locale:       "es_es",
-		NvidiaLocale: "es-es",
-		Currency:     "EUR",
-	},
-	"NOR": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394903600"),
-			},
-			"2070": {
-				SKU: strPtr("5394902600"),
-			},
-			"2080": {
-				SKU: strPtr("5336531700"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218988100"),
-			},
-			"3080": {
-				SKU: strPtr("5438797200"),
-			},
-			"3090": {
-				SKU: strPtr("5438797100"),
-			},
-		},
-		Locale:       "no_no",
-		NvidiaLocale: "no-no",
-		Currency:     "NOK",
-	},
-	"NLD": {
-		Models: map[string]Model{
-			"2060": {
-				SKU: strPtr("5394903500"),
-			},
-			"2070": {
-				SKU: strPtr("5394902200"),
-			},
-			"2080": {
-				SKU: strPtr("5336532100"),
-			},
-			"2080TI": {
-				SKU: strPtr("5218614400"),
-			},
-			"3080": {
-				SKU: strPtr("5438796700"),
-			},
-			"3090": {
-				SKU: strPtr("5438796600"),
-			},
-		},
-		Locale:       "nl_nl",
-		NvidiaLocale: "nl-nl",
-		Currency:     "EUR",
-	},
-}
-
-func getToast() (*ToastConfig, error) {
-	var err error
-
-	switch runtime.GOOS {
-	case "linux":
-		return &ToastConfig{"linux"}, nil
-	case "windows":
-		return &ToastConfig{"windows"}, nil
-	case "darwin":
-		return &ToastConfig{"darwin"}, nil
-	default:
-		err = fmt.Errorf("unsupported platform")
-	}
-
-	return nil, err
-}
-
-//getTwitter Generates TwitterConfiguration for application from environmental variables.
-func getTwitter() (*TwitterConfig, error) {
-	c := TwitterConfig{}
-
-	key, keyOk := os.LookupEnv("TWITTER_CONSUMER_KEY")
-	if keyOk == false {
-		return nil, &ConfigError{"Twitter", "TWITTER_CONSUMER_KEY"}
-	}
-	c.ConsumerKey = key
-
-	cs, csOk := os.LookupEnv("TWITTER_CONSUMER_SECRET")
-	if csOk == false {
-		return nil, &ConfigError{"Twitter", "TWITTER_CONSUMER_SECRET"}
-	}
-	c.ConsumerSecret = cs
-
-	at, atOk := os.LookupEnv("TWITTER_ACCESS_TOKEN")
-	if atOk == false {
-		return nil, &ConfigError{"Twitter", "TWITTER_ACCESS_TOKEN"}
+//getNotifier Generates the settings of a notification channel for application from environmental variables.
+func getNotifier(name string) (interface{}, error) {
+	section, ok := sections[name]
+	if ok == false {
+		return nil, fmt.Errorf("%s: unknown notifier, choose one of %v", name, sectionNames())
 	}
 
-	c.AccessToken = at
+	settings := section.New()
+	r := Resolver{name: name}
+	section.Resolve(settings, &r)
 
-	as, asOk := os.LookupEnv("TWITTER_ACCESS_SECRET")
-	if asOk == false {
-		return nil, &ConfigError{"Twitter", "TWITTER_ACCESS_SECRET"}
+	if len(r.errs) > 0 {
+		return nil, r.errs
 	}
 
-	c.AccessSecret = as
-
-	return &c, nil
+	return settings, nil
 }
 
-//getTwilio Generates TwilioConfiguration for application from environmental variables.
-func getTwilio() (*TwilioConfig, error) {
-	c := TwilioConfig{}
-
-	id, idOk := os.LookupEnv("TWILIO_ACCOUNT_SID")
-	if idOk == false {
-		return nil, &ConfigError{"Twilio", "TWILIO_ACCOUNT_SID"}
-	}
-	c.AccountSID = id
-
-	t, tOk := os.LookupEnv("TWILIO_TOKEN")
-	if tOk == false {
-		return nil, &ConfigError{"Twilio", "TWILIO_TOKEN"}
-	}
-	c.Token = t
-
-	sn, snOk := os.LookupEnv("TWILIO_SOURCE_NUMBER")
-	if snOk == false {
-		return nil, &ConfigError{"Twilio", "TWILIO_SOURCE_NUMBER"}
-	}
-	c.SourceNumber = sn
+//getShields Generates ShieldsConfig for application from environmental variables.
+func getShields() (*ShieldsConfig, error) {
+	c := ShieldsConfig{}
 
-	dn, dnOk := os.LookupEnv("TWILIO_DESTINATION_NUMBER")
-	if dnOk == false {
-		return nil, &ConfigError{"Twilio", "TWILIO_DESTINATION_NUMBER"}
+	p, pOk := os.LookupEnv("PORT")
+	if pOk == false {
+		return nil, &ConfigError{"Shields", "PORT"}
 	}
-	c.DestinationNumber = dn
+	c.Port = p
 
 	return &c, nil
 }
 
-//getDiscord Generates DiscordConfiguration for application from environmental variables.
-func getDiscord() (*DiscordConfig, error) {
-	c := DiscordConfig{}
-
-	u, uOk := os.LookupEnv("DISCORD_WEBHOOK_URL")
-	if uOk == false {
-		return nil, &ConfigError{"Discord", "DISCORD_WEBHOOK_URL"}
+// getAPI Generates APIConfig for application, environmental variables take precedence over the given values.
+func getAPI(c APIConfig) APIConfig {
+	if v, ok := os.LookupEnv("NVIDIA_STORE_URL"); ok {
+		c.StoreURL = v
 	}
-	c.WebhookURL = u
 
-	return &c, nil
-}
-
-//getTelegram Generates TelegramConfiguration for application from environmental variables.
-func getTelegram() (*TelegramConfig, error) {
-	c := TelegramConfig{}
-
-	a, aOk := os.LookupEnv("TELEGRAM_API_KEY")
-	if aOk == false {
-		return nil, &ConfigError{"Telegram", "TELEGRAM_API_KEY"}
+	if v, ok := os.LookupEnv("NVIDIA_API_URL"); ok {
+		c.APIURL = v
 	}
-	c.APIKey = a
 
-	id, idOk := os.LookupEnv("TELEGRAM_CHAT_ID")
-	if idOk == false {
-		return nil, &ConfigError{"Telegram", "TELEGRAM_CHAT_ID"}
+	if v, ok := os.LookupEnv("NVIDIA_USER_AGENT"); ok {
+		c.UserAgent = v
 	}
 
-	c.ChatID = id
-
-	return &c, nil
-}
-
-//getShields Generates ShieldsConfig for application from environmental variables.
-func getShields() (*ShieldsConfig, error) {
-	c := ShieldsConfig{}
-
-	p, pOk := os.LookupEnv("PORT")
-	if pOk == false {
-		return nil, &ConfigError{"Shields", "PORT"}
-	}
-	c.Port = p
-
-	return &c, nil
+	return c
 }
 
 //getSystem Generates SystemConfig for application from environmental variables.
@@ -726,83 +233,124 @@ func getSystem() (*SystemConfig, error) {
 	return &c, nil
 }
 
-//Get Generates Configuration for application from environmental variables.
-func Get(region string, model string, delay int64, sms bool, discord bool, twitter bool, telegram bool, toast bool, shields bool, update bool) (*Config, error) {
-	if regionConfig, ok := RegionalConfigs[region]; ok {
-		models := getSupportedModels(RegionalConfigs[region])
-		isSupportedModel := contains(models, model)
-		if isSupportedModel == false {
-			log.Println(fmt.Sprintf("Please choose one of the following supported models: %v by using -model=XXX", models))
-			return nil, &ModelError{"unsupported model error"}
-		}
-		configuration := Config{}
-		configuration.SKU = regionConfig.Models[model].SKU
-		configuration.Delay = delay
-		configuration.Locale = regionConfig.Locale
-		configuration.NvidiaLocale = regionConfig.NvidiaLocale
-		configuration.Currency = regionConfig.Currency
-
-		if sms == true {
-			cfg, err := getTwilio()
-			if err != nil {
-				return nil, err
-			}
-			configuration.TwilioConfig = cfg
-		}
+//Get Generates Configuration for application from environmental variables, notifiers names every notification channel to enable.
+func Get(regions []string, models []string, delay time.Duration, notifiers []string, shields bool, update bool) (*Config, error) {
+	watches, err := getWatches(regions, models)
+	if err != nil {
+		return nil, err
+	}
 
-		if discord == true {
-			cfg, err := getDiscord()
-			if err != nil {
-				return nil, err
-			}
-			configuration.DiscordConfig = cfg
-		}
+	configuration := Config{}
+	configuration.Delay = delay
+	configuration.Watches = watches
+	configuration.APIConfig = getAPI(APIConfig{})
 
-		if twitter == true {
-			cfg, err := getTwitter()
-			if err != nil {
-				return nil, err
-			}
-			configuration.TwitterConfig = cfg
+	for _, name := range notifiers {
+		settings, err := getNotifier(name)
+		if err != nil {
+			return nil, err
 		}
+		configuration.setNotifier(name, settings)
+	}
 
-		if telegram == true {
-			cfg, err := getTelegram()
-			if err != nil {
-				return nil, err
-			}
-			configuration.TelegramConfig = cfg
+	if shields == true {
+		cfg, err := getShields()
+		if err != nil {
+			return nil, err
 		}
+		configuration.ShieldsConfig = cfg
+	}
 
-		if toast == true {
-			cfg, err := getToast()
-			if err != nil {
-				return nil, err
-			}
-			configuration.ToastConfig = cfg
+	if update == true {
+		cfg, err := getSystem()
+		if err != nil {
+			return nil, err
 		}
+		configuration.SystemConfig = cfg
+	}
 
-		if shields == true {
-			cfg, err := getShields()
-			if err != nil {
-				return nil, err
-			}
-			configuration.ShieldsConfig = cfg
+	return &configuration, nil
+}
+
+// getWatches Generates a Watch for every combination of the requested regions and models.
+func getWatches(regions []string, models []string) ([]Watch, error) {
+	watches := []Watch{}
+
+	for _, region := range regions {
+		regionConfig, ok := RegionalConfigs[region]
+		if ok == false {
+			log.Println(fmt.Sprintf("Please choose one of the following supported regions: %v by using -region=XXX", getSupportedRegions()))
+			return nil, &RegionError{region}
 		}
 
-		if update == true {
-			cfg, err := getSystem()
-			if err != nil {
-				return nil, err
+		for _, model := range models {
+			supported := getSupportedModels(regionConfig)
+			if contains(supported, model) == false {
+				log.Println(fmt.Sprintf("Please choose one of the following supported models for %s: %v by using -model=XXX", region, supported))
+				return nil, &ModelError{model}
 			}
-			configuration.SystemConfig = cfg
+
+			watches = append(watches, newWatch(region, model, regionConfig))
 		}
+	}
+
+	if len(watches) < 1 {
+		return nil, &RegionError{""}
+	}
+
+	return watches, nil
+}
+
+// newWatch Generates a Watch for a model using the locale and currency of its region.
+func newWatch(region string, model string, config RegionalConfig) Watch {
+	return Watch{
+		Region:       region,
+		Model:        model,
+		SKU:          *config.Models[model].SKU,
+		Locale:       config.Locale,
+		NvidiaLocale: config.NvidiaLocale,
+		Currency:     config.Currency,
+		ProductURL:   RenderProductURL(config.Models[model].ProductURL, config.NvidiaLocale),
+	}
+}
+
+// RenderProductURL Generates a product page URL from a catalog template for an NVIDIA locale E.X. en-us.
+func RenderProductURL(template string, nvidiaLocale string) string {
+	if template == "" {
+		template = DefaultProductURL
+	}
+
+	return strings.ReplaceAll(template, "{nvidiaLocale}", nvidiaLocale)
+}
+
+// ParseDelay Parses a polling delay E.X. 750ms or 2s, plain numbers are milliseconds for backwards compatibility.
+func ParseDelay(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// MinorUnits Converts a price in a currencies major unit E.X. 699.99 into its minor unit E.X. 69999.
+func MinorUnits(price float64) int64 {
+	return int64(math.Round(price * 100))
+}
 
-		return &configuration, nil
+// SplitList Splits a comma separated flag value into its trimmed, non-empty elements.
+func SplitList(in string) []string {
+	out := []string{}
+
+	for _, v := range strings.Split(in, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			out = append(out, v)
+		}
 	}
 
-	log.Println(fmt.Sprintf("Please choose one of the following supported regions: %v by using -region=XXX", getSupportedRegions()))
-	return nil, &RegionError{region}
+	return out
 }
 
 // contains Determins if a string exists in a slice of strings.
@@ -815,30 +363,19 @@ func contains(s []string, e string) bool {
 	return false
 }
 
-// getSupportedRegions Gets a list of all supported region names
+// getSupportedRegions Gets a list of all supported region names from the loaded catalog
 func getSupportedRegions() []string {
-	keys := []string{}
-
-	for k := range RegionalConfigs {
-		keys = append(keys, k)
-	}
-
-	return keys
+	return RegionalConfigs.regions()
 }
 
-// getSupportedModels Gets a list of all supported model names in a particular region
+// getSupportedModels Gets a sorted list of all supported model names in a particular region
 func getSupportedModels(config RegionalConfig) []string {
 	keys := []string{}
 
 	for k := range config.Models {
 		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 
 	return keys
 }
-
-// strPtr generates a pointer version of a string
-func strPtr(in string) *string {
-	i := in
-	return &i
-}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -18,160 +19,59 @@ func resetEnv(env []string) func() {
 	}
 }
 
-func envSMS() func() {
-	vars := []string{"TWILIO_ACCOUNT_SID=1", "TWILIO_TOKEN=2", "TWILIO_SOURCE_NUMBER=3", "TWILIO_DESTINATION_NUMBER=4"}
-
-	return func() {
-		for _, e := range vars {
-			pair := strings.SplitN(e, "=", 2)
-			os.Setenv(pair[0], pair[1])
-		}
-	}
-}
-
-func envDiscord() func() {
-	vars := []string{"DISCORD_WEBHOOK_URL=1"}
-
-	return func() {
-		for _, e := range vars {
-			pair := strings.SplitN(e, "=", 2)
-			os.Setenv(pair[0], pair[1])
-		}
-	}
-}
-
-func envTwitter() func() {
-	vars := []string{"TWITTER_CONSUMER_KEY=1", "TWITTER_CONSUMER_SECRET=2", "TWITTER_ACCESS_TOKEN=3", "TWITTER_ACCESS_SECRET=4"}
-
-	return func() {
-		for _, e := range vars {
-			pair := strings.SplitN(e, "=", 2)
-			os.Setenv(pair[0], pair[1])
-		}
-	}
-}
-
-func envTelegram() func() {
-	vars := []string{"TELEGRAM_API_KEY=1", "TELEGRAM_CHAT_ID=2"}
-
-	return func() {
-		for _, e := range vars {
-			pair := strings.SplitN(e, "=", 2)
-			os.Setenv(pair[0], pair[1])
-		}
+func usaWatches() []Watch {
+	return []Watch{
+		{
+			Region:       "USA",
+			Model:        "3080",
+			SKU:          "5438481700",
+			Locale:       "en_us",
+			NvidiaLocale: "en-us",
+			Currency:     "USD",
+			ProductURL:   "https://www.nvidia.com/en-us/geforce/graphics-cards/30-series/rtx-3080/",
+		},
 	}
 }
 
 func TestGet(t *testing.T) {
 	tests := map[string]struct {
 		region      string
-		delay       int64
-		sms         bool
-		discord     bool
-		twitter     bool
-		telegram    bool
-		desktop     bool
+		delay       time.Duration
+		notifiers   []string
 		environment func()
 		expected    *Config
 	}{
 		"default": {
 			region:      "USA",
-			delay:       500,
-			sms:         false,
-			discord:     false,
-			twitter:     false,
-			telegram:    false,
-			desktop:     false,
+			delay:       500 * time.Millisecond,
 			environment: func() {},
 			expected: &Config{
-				Locale:       "en_us",
-				NvidiaLocale: "en-us",
-				Currency:     "USD",
-				Delay:        500,
-				SKU:          strPtr("5438481700"),
+				Delay:   500 * time.Millisecond,
+				Watches: usaWatches(),
 			},
 		},
-		"with sms": {
+		"with chat": {
 			region:      "USA",
-			sms:         true,
-			discord:     false,
-			twitter:     false,
-			telegram:    false,
-			desktop:     false,
-			environment: envSMS(),
+			notifiers:   []string{"chat"},
+			environment: envChat(),
 			expected: &Config{
-				Locale:       "en_us",
-				NvidiaLocale: "en-us",
-				Currency:     "USD",
-				Delay:        0,
-				SKU:          strPtr("5438481700"),
-				TwilioConfig: &TwilioConfig{
-					AccountSID:        "1",
-					Token:             "2",
-					SourceNumber:      "3",
-					DestinationNumber: "4",
+				Delay:   0,
+				Watches: usaWatches(),
+				Notifiers: map[string]interface{}{
+					"chat": &chatConfig{URL: "https://chat.example.com", Room: "1"},
 				},
 			},
 		},
-		"with discord": {
+		"with chat and beep": {
 			region:      "USA",
-			sms:         false,
-			discord:     true,
-			twitter:     false,
-			telegram:    false,
-			desktop:     false,
-			environment: envDiscord(),
+			notifiers:   []string{"chat", "beep"},
+			environment: envChat(),
 			expected: &Config{
-				Locale:       "en_us",
-				NvidiaLocale: "en-us",
-				Currency:     "USD",
-				Delay:        0,
-				SKU:          strPtr("5438481700"),
-				DiscordConfig: &DiscordConfig{
-					WebhookURL: "1",
-				},
-			},
-		},
-		"with twitter": {
-			region:      "USA",
-			sms:         false,
-			discord:     false,
-			twitter:     true,
-			telegram:    false,
-			desktop:     false,
-			environment: envTwitter(),
-			expected: &Config{
-				Locale:       "en_us",
-				NvidiaLocale: "en-us",
-				Currency:     "USD",
-				Delay:        0,
-				SKU:          strPtr("5438481700"),
-				TwitterConfig: &TwitterConfig{
-					ConsumerKey:    "1",
-					ConsumerSecret: "2",
-					AccessToken:    "3",
-					AccessSecret:   "4",
-				},
-			},
-		},
-		"with telegram": {
-			region:      "USA",
-			sms:         false,
-			discord:     false,
-			twitter:     false,
-			telegram:    true,
-			desktop:     false,
-			environment: envTelegram(),
-			expected: &Config{
-				Locale:       "en_us",
-				NvidiaLocale: "en-us",
-
-				Currency: "USD",
-				Delay:    0,
-				SKU:      strPtr("5438481700"),
-				TelegramConfig: &TelegramConfig{
-					APIKey: "1",
-					ChatID: "2",
+				Delay:   0,
+				Watches: usaWatches(),
+				Notifiers: map[string]interface{}{
+					"chat": &chatConfig{URL: "https://chat.example.com", Room: "1"},
+					"beep": &beepConfig{On: true},
 				},
 			},
 		},
@@ -183,7 +83,7 @@ func TestGet(t *testing.T) {
 
 			test.environment()
 
-			result, err := Get(test.region, "3080", test.delay, test.sms, test.discord, test.twitter, test.telegram, test.desktop, false, false)
+			result, err := Get([]string{test.region}, []string{"3080"}, test.delay, test.notifiers, false, false)
 			if err != nil {
 				t.Errorf(err.Error())
 			}
@@ -191,3 +91,76 @@ func TestGet(t *testing.T) {
 		})
 	}
 }
+
+func TestGetNotifier(t *testing.T) {
+	defer resetEnv(os.Environ())
+	defer os.Unsetenv("CHAT_TOKEN")
+	os.Unsetenv("CHAT_URL")
+	os.Unsetenv("CHAT_ROOM")
+	os.Setenv("CHAT_TOKEN", "secret")
+
+	_, err := getNotifier("chat")
+	assert.Equal(t, "chat: CHAT_URL environment variable not found\nchat: CHAT_ROOM environment variable not found", err.Error())
+
+	os.Setenv("CHAT_URL", "chat.example.com")
+	os.Setenv("CHAT_ROOM", "1")
+
+	_, err = getNotifier("chat")
+	assert.Equal(t, "chat: chat.example.com: url must be an absolute http or https URL", err.Error())
+
+	os.Setenv("CHAT_URL", "https://chat.example.com")
+
+	settings, err := getNotifier("chat")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, &chatConfig{URL: "https://chat.example.com", Room: "1", Token: "secret"}, settings)
+
+	_, err = getNotifier("pager")
+	assert.Equal(t, "pager: unknown notifier, choose one of [beep chat]", err.Error())
+}
+
+func TestGetMultipleWatches(t *testing.T) {
+	result, err := Get([]string{"DEU", "NLD"}, []string{"3080", "3090"}, 0, nil, false, false)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	expected := []Watch{
+		{Region: "DEU", Model: "3080", SKU: *RegionalConfigs["DEU"].Models["3080"].SKU, Locale: "de_de", NvidiaLocale: "de-de", Currency: "EUR", ProductURL: "https://www.nvidia.com/de-de/geforce/graphics-cards/30-series/rtx-3080/"},
+		{Region: "DEU", Model: "3090", SKU: *RegionalConfigs["DEU"].Models["3090"].SKU, Locale: "de_de", NvidiaLocale: "de-de", Currency: "EUR", ProductURL: "https://www.nvidia.com/de-de/geforce/graphics-cards/30-series/rtx-3090/"},
+		{Region: "NLD", Model: "3080", SKU: "5438796700", Locale: "nl_nl", NvidiaLocale: "nl-nl", Currency: "EUR", ProductURL: "https://www.nvidia.com/nl-nl/geforce/graphics-cards/30-series/rtx-3080/"},
+		{Region: "NLD", Model: "3090", SKU: "5438796600", Locale: "nl_nl", NvidiaLocale: "nl-nl", Currency: "EUR", ProductURL: "https://www.nvidia.com/nl-nl/geforce/graphics-cards/30-series/rtx-3090/"},
+	}
+	assert.Equal(t, expected, result.Watches)
+}
+
+func TestGetUnsupportedModel(t *testing.T) {
+	_, err := Get([]string{"USA", "CAN"}, []string{"2080"}, 0, nil, false, false)
+	assert.Equal(t, &ModelError{"2080"}, err)
+}
+
+func TestSplitList(t *testing.T) {
+	assert.Equal(t, []string{"DEU", "AUT", "NLD"}, SplitList("DEU, AUT,,NLD "))
+	assert.Equal(t, []string{}, SplitList(""))
+}
+
+func TestParseDelay(t *testing.T) {
+	tests := map[string]time.Duration{
+		"750ms": 750 * time.Millisecond,
+		"2s":    2 * time.Second,
+		"1000":  time.Second,
+		" 1 ":   time.Millisecond,
+	}
+
+	for value, expected := range tests {
+		delay, err := ParseDelay(value)
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+		assert.Equal(t, expected, delay, value)
+	}
+
+	_, err := ParseDelay("soon")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,626 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileError represents a single problem found while loading a configuration file.
+type FileError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e *FileError) Error() string {
+	if e.File == "" {
+		return e.Message
+	}
+
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+
+	return fmt.Sprintf("%s: %s", e.File, e.Message)
+}
+
+// FileErrors represents every problem found while loading a configuration file.
+type FileErrors []*FileError
+
+func (e FileErrors) Error() string {
+	messages := []string{}
+
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// FileWatch represents a set of regions and models to monitor in a configuration file.
+type FileWatch struct {
+	Regions  []string `yaml:"regions"`
+	Models   []string `yaml:"models"`
+	MaxPrice float64  `yaml:"max_price"`
+
+	// Schedule polls at different rates through the week instead of every delay.
+	Schedule *FileSchedule `yaml:"schedule"`
+}
+
+// FileNotifiers represents the notification channels in a configuration file keyed by name, each either true, false or the settings of its registered Section.
+type FileNotifiers map[string]yaml.Node
+
+// File represents a YAML configuration file, E.X.
+//
+//	catalog: https://example.com/catalog.json
+//	delay: 750ms
+//	jitter: 500ms
+//	history: history.db
+//	watches:
+//	  - regions: [DEU, AUT, NLD]
+//	    models: [3080, 3090]
+//	    max_price: 750
+//	    schedule:
+//	      timezone: Europe/Berlin
+//	      delay: 60s
+//	      windows:
+//	        - days: mon-fri
+//	          hours: 14:00-16:00
+//	          delay: 2s
+//	          jitter: 0s
+//	api:
+//	  api_url: http://localhost:8080
+//	notifiers:
+//	  discord:
+//	    webhook_url: https://discord.com/api/webhooks/...
+//	templates:
+//	  discord:
+//	    in_stock: "{{.Summary}} is in stock {{.CartURL}}"
+type File struct {
+	Catalog      string                       `yaml:"catalog"`
+	Delay        string                       `yaml:"delay"`
+	Jitter       *time.Duration               `yaml:"jitter"`
+	RateLimit    float64                      `yaml:"rate_limit"`
+	Remote       bool                         `yaml:"remote"`
+	Update       *bool                        `yaml:"update"`
+	Watches      []FileWatch                  `yaml:"watches"`
+	Transitions  []string                     `yaml:"transitions"`
+	Cooldown     time.Duration                `yaml:"cooldown"`
+	PriceChanges bool                         `yaml:"price_changes"`
+	History      string                       `yaml:"history"`
+	API          APIConfig                    `yaml:"api"`
+	Notifiers    FileNotifiers                `yaml:"notifiers"`
+	Templates    map[string]map[string]string `yaml:"templates"`
+
+	path  string
+	root  *yaml.Node
+	flags map[string]bool
+
+	// errs problems found while decoding the file.
+	errs FileErrors
+}
+
+var lineRegexp = regexp.MustCompile(`line (\d+): (.*)`)
+
+// ReadFile Reads and decodes a YAML configuration file, only invalid YAML fails here and everything else is reported by Config.
+func ReadFile(path string) (*File, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseFile(path, data)
+}
+
+func parseFile(path string, data []byte) (*File, error) {
+	f := File{path: path, root: &yaml.Node{}}
+
+	err := yaml.Unmarshal(data, f.root)
+	if err != nil {
+		return nil, f.decodeErrors(err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	// Values of the wrong type or unknown fields don't stop decoding, Config reports them with every other problem.
+	err = decoder.Decode(&f)
+	if err != nil && err != io.EOF {
+		if _, ok := err.(*yaml.TypeError); ok == false {
+			return nil, f.decodeErrors(err)
+		}
+		f.errs = f.decodeErrors(err)
+	}
+
+	return &f, nil
+}
+
+// Set Overrides a configuration file value using a command line flag name and value.
+func (f *File) Set(name string, value string) error {
+	if f.flags == nil {
+		f.flags = map[string]bool{}
+	}
+
+	switch name {
+	case "region", "model":
+		if len(f.Watches) == 0 {
+			f.Watches = []FileWatch{{}}
+		}
+
+		for i := range f.Watches {
+			if name == "region" {
+				f.Watches[i].Regions = SplitList(value)
+			} else {
+				f.Watches[i].Models = SplitList(value)
+			}
+		}
+	case "max-price":
+		maxPrice, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+
+		if len(f.Watches) == 0 {
+			f.Watches = []FileWatch{{}}
+		}
+
+		for i := range f.Watches {
+			f.Watches[i].MaxPrice = maxPrice
+		}
+	case "catalog":
+		f.Catalog = value
+	case "history":
+		f.History = value
+	case "transitions":
+		f.Transitions = SplitList(value)
+	case "cooldown":
+		cooldown, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		f.Cooldown = cooldown
+	case "delay":
+		_, err := ParseDelay(value)
+		if err != nil {
+			return err
+		}
+		f.Delay = value
+	case "jitter":
+		jitter, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		f.Jitter = &jitter
+	case "rate-limit":
+		rateLimit, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		f.RateLimit = rateLimit
+	case "remote", "update", "price-changes":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.setBool(name, enabled)
+	default:
+		if _, ok := sections[name]; ok == false {
+			return nil
+		}
+
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.setNotifier(name, enabled)
+	}
+
+	f.flags[name] = true
+
+	return nil
+}
+
+func (f *File) setBool(name string, enabled bool) {
+	switch name {
+	case "remote":
+		f.Remote = enabled
+	case "update":
+		f.Update = &enabled
+	case "price-changes":
+		f.PriceChanges = enabled
+	}
+}
+
+// setNotifier Enables or disables a notification channel, enabling keeps any settings it already has.
+func (f *File) setNotifier(name string, enabled bool) {
+	if enabled == false {
+		delete(f.Notifiers, name)
+		return
+	}
+
+	if f.Notifiers == nil {
+		f.Notifiers = FileNotifiers{}
+	}
+
+	if node, ok := f.Notifiers[name]; ok == false || node.Kind != yaml.MappingNode {
+		f.Notifiers[name] = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+}
+
+// Config Generates Configuration for application from a configuration file, environmental variables take precedence over file values.
+func (f *File) Config() (*Config, error) {
+	errs := append(FileErrors{}, f.errs...)
+
+	configuration := Config{}
+	configuration.Remote = f.Remote
+	configuration.Templates = f.Templates
+	configuration.Transitions = f.Transitions
+	configuration.Cooldown = f.Cooldown
+	configuration.PriceChanges = f.PriceChanges
+	configuration.History = f.History
+
+	if f.Delay != "" {
+		delay, err := ParseDelay(f.Delay)
+		if err != nil {
+			errs = append(errs, f.errorAt("delay", fmt.Sprintf("%s: delay must be a duration E.X. 750ms or 2s", f.Delay), "delay"))
+		} else if delay < 0 {
+			errs = append(errs, f.errorAt("delay", "delay must not be negative", "delay"))
+		}
+		configuration.Delay = delay
+	}
+
+	configuration.Jitter = DefaultJitter
+	if f.Jitter != nil {
+		configuration.Jitter = *f.Jitter
+		if *f.Jitter < 0 {
+			errs = append(errs, f.errorAt("jitter", "jitter must not be negative", "jitter"))
+		}
+	}
+
+	configuration.RateLimit = f.RateLimit
+	if f.RateLimit < 0 {
+		errs = append(errs, f.errorAt("rate-limit", "rate_limit must not be negative", "rate_limit"))
+	}
+
+	if f.Cooldown < 0 {
+		errs = append(errs, f.errorAt("cooldown", "cooldown must not be negative", "cooldown"))
+	}
+
+	configuration.APIConfig = getAPI(f.API)
+	errs = append(errs, f.checkURL(configuration.APIConfig.StoreURL, "NVIDIA_STORE_URL", "api", "store_url")...)
+	errs = append(errs, f.checkURL(configuration.APIConfig.APIURL, "NVIDIA_API_URL", "api", "api_url")...)
+
+	watches, watchErrs := f.watches(configuration.Delay, configuration.Jitter)
+	errs = append(errs, watchErrs...)
+	configuration.Watches = watches
+
+	for _, name := range f.notifierNames() {
+		settings, notifierErrs := f.notifier(name)
+		errs = append(errs, notifierErrs...)
+		if settings == nil {
+			continue
+		}
+
+		r := Resolver{name: name, file: f}
+		sections[name].Resolve(settings, &r)
+		errs = append(errs, r.errs...)
+		configuration.setNotifier(name, settings)
+	}
+
+	if f.Update == nil || *f.Update == true {
+		cfg, err := getSystem()
+		if err != nil {
+			errs = append(errs, f.errorAt("update", err.Error(), "update"))
+		}
+		configuration.SystemConfig = cfg
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &configuration, nil
+}
+
+// watches Generates a Watch for every region and model combination in the configuration file, schedules default to delay and jitter.
+func (f *File) watches(delay time.Duration, jitter time.Duration) ([]Watch, FileErrors) {
+	errs := FileErrors{}
+	watches := []Watch{}
+
+	if len(f.Watches) == 0 {
+		errs = append(errs, f.errorAt("region", "at least one region and model must be watched", "watches"))
+	}
+
+	for i, w := range f.Watches {
+		if len(w.Regions) == 0 {
+			message := fmt.Sprintf("watch requires at least one region, choose from %v", getSupportedRegions())
+			errs = append(errs, f.errorAt("region", message, "watches", i))
+		}
+
+		if len(w.Models) == 0 {
+			errs = append(errs, f.errorAt("model", "watch requires at least one model", "watches", i))
+		}
+
+		if w.MaxPrice < 0 {
+			errs = append(errs, f.errorAt("max-price", "max_price must not be negative", "watches", i, "max_price"))
+		}
+
+		var schedule *Schedule
+		if w.Schedule != nil {
+			var scheduleErrs FileErrors
+			schedule, scheduleErrs = f.schedule(*w.Schedule, delay, jitter, "watches", i, "schedule")
+			errs = append(errs, scheduleErrs...)
+		}
+
+		for j, region := range w.Regions {
+			regionConfig, ok := RegionalConfigs[region]
+			if ok == false {
+				message := fmt.Sprintf("%s: region unsupported, choose one of %v", region, getSupportedRegions())
+				errs = append(errs, f.errorAt("region", message, "watches", i, "regions", j))
+				continue
+			}
+
+			for k, model := range w.Models {
+				if _, ok := regionConfig.Models[model]; ok == false {
+					message := fmt.Sprintf("%s: model unsupported in %s, choose one of %v", model, region, getSupportedModels(regionConfig))
+					errs = append(errs, f.errorAt("model", message, "watches", i, "models", k))
+					continue
+				}
+
+				watch := newWatch(region, model, regionConfig)
+				watch.MaxPrice = MinorUnits(w.MaxPrice)
+				watch.Schedule = schedule
+				watches = append(watches, watch)
+			}
+		}
+	}
+
+	return watches, errs
+}
+
+// schedule Generates the Schedule of a watch, the delay outside its windows defaults to delay and every jitter to jitter.
+func (f *File) schedule(s FileSchedule, delay time.Duration, jitter time.Duration, path ...interface{}) (*Schedule, FileErrors) {
+	errs := FileErrors{}
+	at := func(keys ...interface{}) []interface{} {
+		return append(append([]interface{}{}, path...), keys...)
+	}
+
+	schedule := &Schedule{Location: time.Local, Delay: delay, Jitter: jitter, Windows: []ScheduleWindow{}}
+
+	if s.Timezone != "" {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			errs = append(errs, f.errorAt("", fmt.Sprintf("%s: timezone must be an IANA time zone E.X. Europe/Berlin", s.Timezone), at("timezone")...))
+		} else {
+			schedule.Location = loc
+		}
+	}
+
+	if s.Delay != "" {
+		var err error
+		schedule.Delay, schedule.Paused, err = parseScheduleDelay(s.Delay)
+		if err != nil {
+			errs = append(errs, f.errorAt("", err.Error(), at("delay")...))
+		}
+	}
+
+	if s.Jitter != nil {
+		schedule.Jitter = *s.Jitter
+		if *s.Jitter < 0 {
+			errs = append(errs, f.errorAt("", "jitter must not be negative", at("jitter")...))
+		}
+	}
+
+	if len(s.Windows) == 0 {
+		errs = append(errs, f.errorAt("", "schedule requires at least one window", at("windows")...))
+	}
+
+	for i, w := range s.Windows {
+		window := ScheduleWindow{Jitter: schedule.Jitter}
+
+		var err error
+		window.Days, err = ParseDays(w.Days)
+		if err != nil {
+			errs = append(errs, f.errorAt("", err.Error(), at("windows", i, "days")...))
+		}
+
+		window.Start, window.End, err = ParseHours(w.Hours)
+		if err != nil {
+			errs = append(errs, f.errorAt("", err.Error(), at("windows", i, "hours")...))
+		}
+
+		window.Delay, window.Paused, err = parseScheduleDelay(w.Delay)
+		if err != nil {
+			errs = append(errs, f.errorAt("", err.Error(), at("windows", i, "delay")...))
+		}
+
+		if w.Jitter != nil {
+			window.Jitter = *w.Jitter
+			if *w.Jitter < 0 {
+				errs = append(errs, f.errorAt("", "jitter must not be negative", at("windows", i, "jitter")...))
+			}
+		}
+
+		schedule.Windows = append(schedule.Windows, window)
+	}
+
+	if _, ok := schedule.Fastest(); ok == false && len(errs) == 0 {
+		errs = append(errs, f.errorAt("", "schedule never polls, set a delay other than off", at("delay")...))
+	}
+
+	return schedule, errs
+}
+
+// notifierNames Gets the name of every notification channel in the configuration file ordered by name.
+func (f *File) notifierNames() []string {
+	names := []string{}
+
+	for name := range f.Notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// notifier Decodes fresh settings for a notification channel from its section of the configuration file, nil when it is switched off.
+func (f *File) notifier(name string) (interface{}, FileErrors) {
+	section, ok := sections[name]
+	if ok == false {
+		message := fmt.Sprintf("%s: unknown notifier, choose one of %v", name, sectionNames())
+		return nil, FileErrors{f.errorAt("", message, "notifiers", name)}
+	}
+
+	node := f.Notifiers[name]
+	if node.Kind == yaml.ScalarNode {
+		enabled := false
+		err := node.Decode(&enabled)
+		if err != nil {
+			return nil, f.decodeErrors(err)
+		}
+
+		if enabled == false {
+			return nil, nil
+		}
+		node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	settings := section.New()
+	err := node.Decode(settings)
+	if err != nil {
+		return nil, f.decodeErrors(err)
+	}
+
+	return settings, f.unknownFields(&node, settings)
+}
+
+// unknownFields Reports keys of a mapping without a matching field in settings, the check KnownFields makes for the rest of the file.
+func (f *File) unknownFields(node *yaml.Node, settings interface{}) FileErrors {
+	t := reflect.TypeOf(settings)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	known := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if key == "" {
+			key = strings.ToLower(t.Field(i).Name)
+		}
+		known[key] = key != "-"
+	}
+
+	errs := FileErrors{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if known[key.Value] == false {
+			errs = append(errs, &FileError{File: f.path, Line: key.Line, Message: fmt.Sprintf("field %s not found in type %s", key.Value, t)})
+		}
+	}
+
+	return errs
+}
+
+// checkURL Reports an optional value that isn't an absolute http or https URL.
+func (f *File) checkURL(value string, env string, path ...interface{}) FileErrors {
+	if value == "" {
+		return nil
+	}
+
+	if IsHTTPURL(value) {
+		return nil
+	}
+
+	message := fmt.Sprintf("%s: must be an absolute http or https URL, set it in the configuration file or with %s", value, env)
+	return FileErrors{f.errorAt("", message, path...)}
+}
+
+// IsHTTPURL Determines if a value is an absolute http or https URL.
+func IsHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// errorAt Generates a FileError for a value set either by a command line flag or at the given path of the file.
+func (f *File) errorAt(flag string, message string, path ...interface{}) *FileError {
+	if f.flags[flag] == true {
+		return &FileError{File: fmt.Sprintf("-%s", flag), Message: message}
+	}
+
+	return &FileError{File: f.path, Line: f.line(path...), Message: message}
+}
+
+// line Finds the closest line number for a path of mapping keys and sequence indexes.
+func (f *File) line(path ...interface{}) int {
+	if f.root == nil || len(f.root.Content) == 0 {
+		return 0
+	}
+
+	line := 0
+	node := f.root.Content[0]
+
+	for _, p := range path {
+		switch key := p.(type) {
+		case string:
+			if node.Kind != yaml.MappingNode {
+				return line
+			}
+
+			found := false
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					line = node.Content[i].Line
+					node = node.Content[i+1]
+					found = true
+					break
+				}
+			}
+
+			if found == false {
+				return line
+			}
+		case int:
+			if node.Kind != yaml.SequenceNode || key >= len(node.Content) {
+				return line
+			}
+
+			node = node.Content[key]
+			line = node.Line
+		}
+	}
+
+	return line
+}
+
+// decodeErrors Converts YAML decoding errors into FileErrors.
+func (f *File) decodeErrors(err error) FileErrors {
+	messages := []string{err.Error()}
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	}
+
+	errs := FileErrors{}
+	for _, message := range messages {
+		match := lineRegexp.FindStringSubmatch(message)
+		if match == nil {
+			errs = append(errs, &FileError{File: f.path, Message: message})
+			continue
+		}
+
+		line, _ := strconv.Atoi(match[1])
+		errs = append(errs, &FileError{File: f.path, Line: line, Message: match[2]})
+	}
+
+	return errs
+}
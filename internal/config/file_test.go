@@ -0,0 +1,445 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileConfig(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Unsetenv("CHAT_URL")
+	os.Unsetenv("CHAT_TOKEN")
+	os.Setenv("CHAT_ROOM", "env")
+
+	data := []byte(`
+delay: 1000
+cooldown: 10m
+update: false
+watches:
+  - regions: [USA]
+    models: [3080]
+notifiers:
+  chat:
+    url: https://chat.example.com
+    room: file
+    token: secret
+  beep: true
+`)
+
+	file, err := parseFile("clerk.yaml", data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	result, err := file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := &Config{
+		Delay:    time.Second,
+		Jitter:   DefaultJitter,
+		Cooldown: 10 * time.Minute,
+		Watches:  usaWatches(),
+		Notifiers: map[string]interface{}{
+			"chat": &chatConfig{URL: "https://chat.example.com", Room: "env", Token: "secret"},
+			"beep": &beepConfig{On: true},
+		},
+	}
+	assert.Equal(t, expected, result)
+
+	os.Setenv("CHAT_ROOM", "other")
+
+	result, err = file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, &chatConfig{URL: "https://chat.example.com", Room: "other", Token: "secret"}, result.Notifiers["chat"], "settings are decoded afresh every time")
+}
+
+func TestFileConfigNotifierSwitches(t *testing.T) {
+	defer resetEnv(os.Environ())
+	envChat()()
+
+	data := []byte(`update: false
+watches:
+  - regions: [USA]
+    models: [3080]
+notifiers:
+  chat: true
+  beep: false
+`)
+
+	file, err := parseFile("clerk.yaml", data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	result, err := file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, map[string]interface{}{"chat": &chatConfig{URL: "https://chat.example.com", Room: "1"}}, result.Notifiers)
+
+	assert.Nil(t, file.Set("chat", "false"))
+	assert.Nil(t, file.Set("beep", "true"))
+	assert.NotNil(t, file.Set("beep", "loud"))
+
+	result, err = file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, map[string]interface{}{"beep": &beepConfig{On: true}}, result.Notifiers)
+}
+
+func TestFileConfigFlagOverrides(t *testing.T) {
+	defer resetEnv(os.Environ())
+	envChat()()
+
+	data := []byte(`
+delay: 1000
+update: false
+watches:
+  - regions: [DEU]
+    models: [3090]
+`)
+
+	file, err := parseFile("clerk.yaml", data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	assert.Nil(t, file.Set("region", "USA"))
+	assert.Nil(t, file.Set("model", "3080"))
+	assert.Nil(t, file.Set("delay", "500ms"))
+	assert.Nil(t, file.Set("rate-limit", "2.5"))
+	assert.NotNil(t, file.Set("delay", "soon"))
+	assert.Nil(t, file.Set("cooldown", "90s"))
+	assert.Nil(t, file.Set("max-price", "749.99"))
+	assert.Nil(t, file.Set("price-changes", "true"))
+	assert.Nil(t, file.Set("history", "clerk.db"))
+	assert.Nil(t, file.Set("chat", "true"))
+	assert.Nil(t, file.Set("beep", "true"))
+	assert.Nil(t, file.Set("pager", "true"), "flags of other commands are ignored")
+
+	result, err := file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	watches := usaWatches()
+	watches[0].MaxPrice = 74999
+
+	expected := &Config{
+		Delay:        500 * time.Millisecond,
+		Jitter:       DefaultJitter,
+		Cooldown:     90 * time.Second,
+		RateLimit:    2.5,
+		PriceChanges: true,
+		History:      "clerk.db",
+		Watches:      watches,
+		Notifiers: map[string]interface{}{
+			"chat": &chatConfig{URL: "https://chat.example.com", Room: "1"},
+			"beep": &beepConfig{On: true},
+		},
+	}
+	assert.Equal(t, expected, result)
+}
+
+func TestFileConfigValidation(t *testing.T) {
+	defer resetEnv(os.Environ())
+	os.Unsetenv("CHAT_URL")
+	os.Setenv("CHAT_ROOM", "1")
+
+	data := []byte(`delay: -1
+update: false
+watches:
+  - regions: [USA, XXX]
+    models: [3080]
+  - regions: [CAN]
+    models: [2080]
+    max_price: -5
+notifiers:
+  chat: {}
+`)
+
+	file, err := parseFile("clerk.yaml", data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = file.Config()
+	errs, ok := err.(FileErrors)
+	if ok == false {
+		t.Fatalf("Expected FileErrors, got %#v", err)
+	}
+
+	assert.Equal(t, 5, len(errs))
+	assert.Equal(t, 1, errs[0].Line)
+	assert.Equal(t, 4, errs[1].Line)
+	assert.Contains(t, errs[1].Message, "XXX: region unsupported")
+	assert.Equal(t, 8, errs[2].Line)
+	assert.Equal(t, "max_price must not be negative", errs[2].Message)
+	assert.Equal(t, 7, errs[3].Line)
+	assert.Contains(t, errs[3].Message, "2080: model unsupported in CAN")
+	assert.Equal(t, 10, errs[4].Line)
+	assert.Equal(t, "clerk.yaml:10: url is required, set it in the configuration file or with CHAT_URL", errs[4].Error())
+
+	assert.Nil(t, file.Set("chat", "true"))
+	os.Setenv("CHAT_URL", "chat.example.com")
+
+	_, err = file.Config()
+	assert.Contains(t, err.Error(), "-chat: chat.example.com: url must be an absolute http or https URL")
+}
+
+func TestFileConfigDecodeErrors(t *testing.T) {
+	defer resetEnv(os.Environ())
+	envChat()()
+
+	data := []byte(`delay: -1
+watch:
+  - regions: [USA]
+watches:
+  - regions: [XXX]
+    models: [3080]
+rate_limit: fast
+notifiers:
+  pager: {}
+  chat:
+    url: https://chat.example.com
+    channel: general
+  beep: loud
+`)
+
+	file, err := parseFile("clerk.yaml", data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Problems decoding the file don't hide the rest.
+	_, err = file.Config()
+	errs, ok := err.(FileErrors)
+	if ok == false {
+		t.Fatalf("Expected FileErrors, got %#v", err)
+	}
+
+	assert.Equal(t, 7, len(errs))
+	assert.Equal(t, "clerk.yaml:2: field watch not found in type config.File", errs[0].Error())
+	assert.Equal(t, "clerk.yaml:7: cannot unmarshal !!str `fast` into float64", errs[1].Error())
+	assert.Equal(t, "clerk.yaml:1: delay must not be negative", errs[2].Error())
+	assert.Equal(t, 5, errs[3].Line)
+	assert.Contains(t, errs[3].Message, "XXX: region unsupported")
+	assert.Equal(t, "clerk.yaml:13: cannot unmarshal !!str `loud` into bool", errs[4].Error())
+	assert.Equal(t, "clerk.yaml:12: field channel not found in type config.chatConfig", errs[5].Error())
+	assert.Equal(t, "clerk.yaml:9: pager: unknown notifier, choose one of [beep chat]", errs[6].Error())
+}
+
+func TestReadFileInvalid(t *testing.T) {
+	_, err := parseFile("clerk.yaml", []byte("delay: [1\n"))
+	errs, ok := err.(FileErrors)
+	if ok == false {
+		t.Fatalf("Expected FileErrors, got %#v", err)
+	}
+	assert.Equal(t, 1, len(errs))
+}
+
+func TestFileConfigAPI(t *testing.T) {
+	defer os.Unsetenv("NVIDIA_API_URL")
+	os.Unsetenv("NVIDIA_STORE_URL")
+	os.Setenv("NVIDIA_API_URL", "http://localhost:8081")
+
+	data := []byte(`update: false
+watches:
+  - regions: [USA]
+    models: [3080]
+api:
+  store_url: http://localhost:8080
+  api_url: https://api-prod.nvidia.com
+  user_agent: clerk
+`)
+
+	file, err := parseFile("clerk.yaml", data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	result, err := file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, APIConfig{StoreURL: "http://localhost:8080", APIURL: "http://localhost:8081", UserAgent: "clerk"}, result.APIConfig)
+
+	os.Setenv("NVIDIA_API_URL", "localhost:8081")
+	_, err = file.Config()
+	errs, ok := err.(FileErrors)
+	if ok == false {
+		t.Fatalf("Expected FileErrors, got %#v", err)
+	}
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, 7, errs[0].Line)
+	assert.Contains(t, errs[0].Message, "must be an absolute http or https URL")
+}
+
+func TestFileConfigDelay(t *testing.T) {
+	data := []byte(`update: false
+delay: 2 seconds
+rate_limit: -1
+watches:
+  - regions: [USA]
+    models: [3080]
+`)
+
+	file, err := parseFile("clerk.yaml", data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = file.Config()
+	errs, ok := err.(FileErrors)
+	if ok == false {
+		t.Fatalf("Expected FileErrors, got %#v", err)
+	}
+
+	assert.Equal(t, 2, len(errs))
+	assert.Equal(t, "clerk.yaml:2: 2 seconds: delay must be a duration E.X. 750ms or 2s", errs[0].Error())
+	assert.Equal(t, "clerk.yaml:3: rate_limit must not be negative", errs[1].Error())
+
+	assert.Nil(t, file.Set("delay", "750ms"))
+	assert.Nil(t, file.Set("rate-limit", "2"))
+
+	result, err := file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, 750*time.Millisecond, result.Delay)
+	assert.Equal(t, 2.0, result.RateLimit)
+}
+
+func TestFileConfigSchedule(t *testing.T) {
+	data := []byte(`update: false
+delay: 5s
+watches:
+  - regions: [DEU]
+    models: [3080]
+    schedule:
+      timezone: Europe/Berlin
+      jitter: 10s
+      windows:
+        - days: mon-fri
+          hours: 14:00-16:00
+          delay: 2s
+          jitter: 0s
+        - days: sat,sun
+          hours: 00:00-24:00
+          delay: off
+`)
+
+	file, err := parseFile("clerk.yaml", data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	result, err := file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	schedule := result.Watches[0].Schedule
+	assert.Equal(t, "Europe/Berlin", schedule.Location.String())
+	assert.Equal(t, 5*time.Second, schedule.Delay, "defaults to the delay")
+	assert.Equal(t, 10*time.Second, schedule.Jitter)
+	assert.Equal(t, ScheduleWindow{Days: [7]bool{false, true, true, true, true, true, false}, Start: 14 * 60, End: 16 * 60, Delay: 2 * time.Second}, schedule.Windows[0])
+	assert.True(t, schedule.Windows[1].Paused)
+	assert.Equal(t, 10*time.Second, schedule.Windows[1].Jitter, "defaults to the jitter of the schedule")
+}
+
+func TestFileConfigJitter(t *testing.T) {
+	data := []byte(`update: false
+jitter: -1s
+watches:
+  - regions: [USA]
+    models: [3080]
+    schedule:
+      windows:
+        - {days: mon, hours: 09:00-10:00, delay: 2s, jitter: -1s}
+`)
+
+	file, err := parseFile("clerk.yaml", data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = file.Config()
+	errs, ok := err.(FileErrors)
+	if ok == false {
+		t.Fatalf("Expected FileErrors, got %#v", err)
+	}
+
+	assert.Equal(t, 2, len(errs))
+	assert.Equal(t, "clerk.yaml:2: jitter must not be negative", errs[0].Error())
+	assert.Equal(t, "clerk.yaml:8: jitter must not be negative", errs[1].Error())
+
+	assert.Nil(t, file.Set("jitter", "250ms"))
+	file.Watches[0].Schedule = nil
+
+	result, err := file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, 250*time.Millisecond, result.Jitter)
+
+	file = &File{Watches: []FileWatch{{Regions: []string{"USA"}, Models: []string{"3080"}}}}
+	file.Set("update", "false")
+
+	result, err = file.Config()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, DefaultJitter, result.Jitter, "defaults when left out")
+}
+
+func TestFileConfigScheduleValidation(t *testing.T) {
+	data := []byte(`update: false
+watches:
+  - regions: [DEU]
+    models: [3080]
+    schedule:
+      timezone: Europe/Nowhere
+      delay: sometimes
+      windows:
+        - days: weekdays
+          hours: 14:00
+          delay: 2s
+        - days: mon
+          hours: 09:00-25:00
+  - regions: [USA]
+    models: [3080]
+    schedule:
+      delay: off
+      windows:
+        - {days: mon, hours: 09:00-10:00, delay: off}
+`)
+
+	file, err := parseFile("clerk.yaml", data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = file.Config()
+	errs, ok := err.(FileErrors)
+	if ok == false {
+		t.Fatalf("Expected FileErrors, got %#v", err)
+	}
+
+	assert.Equal(t, 7, len(errs))
+	assert.Equal(t, "clerk.yaml:6: Europe/Nowhere: timezone must be an IANA time zone E.X. Europe/Berlin", errs[0].Error())
+	assert.Equal(t, 7, errs[1].Line)
+	assert.Equal(t, 9, errs[2].Line)
+	assert.Equal(t, 10, errs[3].Line)
+	assert.Equal(t, 13, errs[4].Line)
+	assert.Equal(t, "clerk.yaml:12: delay is required, a duration E.X. 750ms or 2s, or off", errs[5].Error())
+	assert.Equal(t, "clerk.yaml:17: schedule never polls, set a delay other than off", errs[6].Error())
+}
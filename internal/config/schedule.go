@@ -0,0 +1,253 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// off is the delay of a schedule that doesn't poll at all.
+const off = "off"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Schedule represents how often a watch is polled at different times of the week in a time zone,
+// E.X. every 2s during 14:00-16:00 on weekdays in Europe/Berlin and every minute otherwise.
+type Schedule struct {
+	Location *time.Location
+	Windows  []ScheduleWindow
+
+	// Delay between polls outside every window, ignored when Paused.
+	Delay  time.Duration
+	Paused bool
+
+	// Jitter most random time added to Delay.
+	Jitter time.Duration
+}
+
+// ScheduleWindow represents a weekly time range with its own delay between polls.
+type ScheduleWindow struct {
+	// Days the window starts on, indexed by time.Weekday.
+	Days [7]bool
+
+	// Start and End minutes since midnight, a window ending before it starts runs past midnight.
+	Start int
+	End   int
+
+	Delay  time.Duration
+	Paused bool
+	Jitter time.Duration
+}
+
+// FileSchedule represents the polling schedule of a watch in a configuration file.
+type FileSchedule struct {
+	Timezone string               `yaml:"timezone"`
+	Delay    string               `yaml:"delay"`
+	Jitter   *time.Duration       `yaml:"jitter"`
+	Windows  []FileScheduleWindow `yaml:"windows"`
+}
+
+// FileScheduleWindow represents a time range of a schedule in a configuration file E.X. days: mon-fri, hours: 14:00-16:00.
+type FileScheduleWindow struct {
+	Days   string         `yaml:"days"`
+	Hours  string         `yaml:"hours"`
+	Delay  string         `yaml:"delay"`
+	Jitter *time.Duration `yaml:"jitter"`
+}
+
+// At Gets the delay between polls at t, false when polling is paused.
+func (s *Schedule) At(t time.Time) (time.Duration, bool) {
+	local := t.In(s.Location)
+
+	for _, w := range s.Windows {
+		if w.contains(local) {
+			return w.Delay, w.Paused == false
+		}
+	}
+
+	return s.Delay, s.Paused == false
+}
+
+// JitterAt Gets the most random time added to the delay between polls at t.
+func (s *Schedule) JitterAt(t time.Time) time.Duration {
+	local := t.In(s.Location)
+
+	for _, w := range s.Windows {
+		if w.contains(local) {
+			return w.Jitter
+		}
+	}
+
+	return s.Jitter
+}
+
+// Next Gets the first time after t the delay between polls changes, the zero time when it never does.
+func (s *Schedule) Next(t time.Time) time.Time {
+	local := t.In(s.Location)
+	delay, polling := s.At(t)
+
+	changes := []time.Time{}
+	for day := -1; day <= 7; day++ {
+		date := local.AddDate(0, 0, day)
+
+		for _, w := range s.Windows {
+			for _, minute := range []int{w.Start, w.End} {
+				change := time.Date(date.Year(), date.Month(), date.Day(), minute/60, minute%60, 0, 0, s.Location)
+				if change.After(t) {
+					changes = append(changes, change)
+				}
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i int, j int) bool {
+		return changes[i].Before(changes[j])
+	})
+
+	for _, change := range changes {
+		d, p := s.At(change)
+		if p != polling || (p && d != delay) {
+			return change
+		}
+	}
+
+	return time.Time{}
+}
+
+// Fastest Gets the shortest delay between polls anywhere in the schedule, false when it never polls.
+func (s *Schedule) Fastest() (time.Duration, bool) {
+	fastest, found := s.Delay, s.Paused == false
+
+	for _, w := range s.Windows {
+		if w.Paused == false && (found == false || w.Delay < fastest) {
+			fastest, found = w.Delay, true
+		}
+	}
+
+	return fastest, found
+}
+
+// contains Determines if a local time falls in the window.
+func (w ScheduleWindow) contains(local time.Time) bool {
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+	previous := (day + 6) % 7
+
+	if w.Start < w.End {
+		return w.Days[day] && minute >= w.Start && minute < w.End
+	}
+
+	return (w.Days[day] && minute >= w.Start) || (w.Days[previous] && minute < w.End)
+}
+
+// ParseDays Parses comma separated days of the week and ranges of them E.X. mon-fri or sat,sun.
+func ParseDays(value string) ([7]bool, error) {
+	days := [7]bool{}
+
+	parts := SplitList(strings.ToLower(value))
+	if len(parts) == 0 {
+		return days, fmt.Errorf("at least one day is required E.X. mon-fri or sat,sun")
+	}
+
+	for _, part := range parts {
+		bounds := strings.SplitN(part, "-", 2)
+
+		first, ok := weekdays[strings.TrimSpace(bounds[0])]
+		if ok == false {
+			return days, fmt.Errorf("%s: must be days of the week E.X. mon-fri or sat,sun", value)
+		}
+
+		last := first
+		if len(bounds) == 2 {
+			last, ok = weekdays[strings.TrimSpace(bounds[1])]
+			if ok == false {
+				return days, fmt.Errorf("%s: must be days of the week E.X. mon-fri or sat,sun", value)
+			}
+		}
+
+		for day := first; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+
+	return days, nil
+}
+
+// ParseHours Parses a range of times of the day into minutes since midnight E.X. 14:00-16:00 or 22:00-02:00, 00:00-24:00 is the whole day.
+func ParseHours(value string) (int, int, error) {
+	bounds := strings.SplitN(value, "-", 2)
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("%s: must be a range of times E.X. 14:00-16:00", value)
+	}
+
+	start, err := parseClock(bounds[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %v", value, err)
+	}
+
+	end, err := parseClock(bounds[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %v", value, err)
+	}
+
+	// A range starting where it ends would otherwise run for a whole day past midnight.
+	if start == end || start == 24*60 {
+		return 0, 0, fmt.Errorf("%s: must not be empty, use 00:00-24:00 for the whole day", value)
+	}
+
+	return start, end, nil
+}
+
+// parseClock Parses a time of the day E.X. 14:00 into minutes since midnight, 24:00 is the end of the day.
+func parseClock(value string) (int, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), ":", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("%s: must be a time of the day E.X. 14:00", value)
+	}
+
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 24 {
+		return 0, fmt.Errorf("%s: must be a time of the day E.X. 14:00", value)
+	}
+
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("%s: must be a time of the day E.X. 14:00", value)
+	}
+
+	return hour*60 + minute, nil
+}
+
+// parseScheduleDelay Parses the delay of a schedule, off pauses polling.
+func parseScheduleDelay(value string) (time.Duration, bool, error) {
+	if strings.ToLower(strings.TrimSpace(value)) == off {
+		return 0, true, nil
+	}
+
+	if value == "" {
+		return 0, false, fmt.Errorf("delay is required, a duration E.X. 750ms or 2s, or off")
+	}
+
+	delay, err := ParseDelay(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: delay must be a duration E.X. 750ms or 2s, or off", value)
+	}
+	if delay < 0 {
+		return 0, false, fmt.Errorf("%s: delay must not be negative", value)
+	}
+
+	return delay, false, nil
+}
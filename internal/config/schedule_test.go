@@ -0,0 +1,89 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDays(t *testing.T) {
+	days, err := ParseDays("Mon-Wed, fri")
+	assert.Nil(t, err)
+	assert.Equal(t, [7]bool{false, true, true, true, false, true, false}, days)
+
+	days, err = ParseDays("fri-mon")
+	assert.Nil(t, err)
+	assert.Equal(t, [7]bool{true, true, false, false, false, true, true}, days, "ranges wrap around the week")
+
+	_, err = ParseDays("weekdays")
+	assert.NotNil(t, err)
+
+	_, err = ParseDays("")
+	assert.NotNil(t, err)
+}
+
+func TestParseHours(t *testing.T) {
+	start, end, err := ParseHours("14:00-16:30")
+	assert.Nil(t, err)
+	assert.Equal(t, 14*60, start)
+	assert.Equal(t, 16*60+30, end)
+
+	start, end, err = ParseHours("00:00-24:00")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, start)
+	assert.Equal(t, 24*60, end)
+
+	for _, value := range []string{"14:00", "14-16", "14:00-24:30", "25:00-26:00", "9:60-10:00", "14:00-14:00", "24:00-02:00"} {
+		_, _, err = ParseHours(value)
+		assert.NotNil(t, err, value)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	weekdays, _ := ParseDays("mon-fri")
+	saturday, _ := ParseDays("sat")
+
+	schedule := &Schedule{
+		Location: berlin,
+		Delay:    time.Minute,
+		Windows: []ScheduleWindow{
+			{Days: weekdays, Start: 14 * 60, End: 16 * 60, Delay: 2 * time.Second},
+			{Days: saturday, Start: 22 * 60, End: 6 * 60, Paused: true},
+		},
+	}
+
+	// Thursday 2020-09-17 14:30 in Berlin.
+	at := time.Date(2020, 9, 17, 12, 30, 0, 0, time.UTC)
+	delay, polling := schedule.At(at)
+	assert.True(t, polling)
+	assert.Equal(t, 2*time.Second, delay)
+	assert.True(t, schedule.Next(at).Equal(time.Date(2020, 9, 17, 16, 0, 0, 0, berlin)))
+
+	at = time.Date(2020, 9, 17, 16, 0, 0, 0, berlin)
+	delay, polling = schedule.At(at)
+	assert.True(t, polling)
+	assert.Equal(t, time.Minute, delay, "windows end before their end time")
+	assert.True(t, schedule.Next(at).Equal(time.Date(2020, 9, 18, 14, 0, 0, 0, berlin)))
+
+	// Friday 16:00 to Saturday 22:00 polls every minute, then pauses past midnight until Sunday 06:00.
+	at = time.Date(2020, 9, 18, 16, 0, 0, 0, berlin)
+	assert.True(t, schedule.Next(at).Equal(time.Date(2020, 9, 19, 22, 0, 0, 0, berlin)))
+
+	at = time.Date(2020, 9, 20, 3, 0, 0, 0, berlin)
+	_, polling = schedule.At(at)
+	assert.False(t, polling)
+	assert.True(t, schedule.Next(at).Equal(time.Date(2020, 9, 20, 6, 0, 0, 0, berlin)))
+
+	fastest, polling := schedule.Fastest()
+	assert.True(t, polling)
+	assert.Equal(t, 2*time.Second, fastest)
+
+	always := &Schedule{Location: berlin, Delay: time.Second, Windows: []ScheduleWindow{{Days: weekdays, Start: 0, End: 24 * 60, Delay: time.Second}}}
+	assert.True(t, always.Next(at).IsZero(), "the delay never changes")
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Section describes the settings of a notification channel, channels register their own so configuration files, flags and environment variables never name them here.
+type Section struct {
+	// Name of the channel, doubling as its command line flag and its key under notifiers in a configuration file.
+	Name string
+
+	// New Generates the empty settings the section of a configuration file decodes into, a pointer to a struct with yaml tags.
+	New func() interface{}
+
+	// Resolve Applies environment variables and defaults to decoded settings, reporting any problem with them to r.
+	Resolve func(settings interface{}, r *Resolver)
+}
+
+var sections = map[string]Section{}
+
+// RegisterSection Adds the settings of a notification channel, alert.Register calls it for every channel.
+func RegisterSection(section Section) {
+	if _, ok := sections[section.Name]; ok {
+		panic(fmt.Sprintf("config: section %s registered twice", section.Name))
+	}
+
+	sections[section.Name] = section
+}
+
+// sectionNames Gets the name of every registered section ordered by name.
+func sectionNames() []string {
+	names := []string{}
+
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Resolver applies environment variables to the settings of a section and collects any problems with them.
+type Resolver struct {
+	name string
+
+	// file the settings were read from, nil when they only come from environment variables.
+	file *File
+	errs FileErrors
+}
+
+// Required Overrides a value with an environment variable when it is set, reporting the value when it is still empty.
+func (r *Resolver) Required(key string, env string, value *string) {
+	r.Optional(env, value)
+
+	if *value != "" {
+		return
+	}
+
+	if r.file == nil {
+		r.Report(fmt.Sprintf("%s environment variable not found", env))
+		return
+	}
+
+	r.Report(fmt.Sprintf("%s is required, set it in the configuration file or with %s", key, env), key)
+}
+
+// Optional Overrides a value with an environment variable when it is set.
+func (r *Resolver) Optional(env string, value *string) {
+	if v, ok := os.LookupEnv(env); ok {
+		*value = v
+	}
+}
+
+// List Overrides values with a comma separated environment variable when it is set.
+func (r *Resolver) List(env string, values *[]string) {
+	if v, ok := os.LookupEnv(env); ok {
+		*values = SplitList(v)
+	}
+}
+
+// Report Adds a problem with the settings, path locates the value within the section E.X. "to", 1.
+func (r *Resolver) Report(message string, path ...interface{}) {
+	if r.file == nil {
+		r.errs = append(r.errs, &FileError{Message: fmt.Sprintf("%s: %s", r.name, message)})
+		return
+	}
+
+	r.errs = append(r.errs, r.file.errorAt(r.name, message, append([]interface{}{"notifiers", r.name}, path...)...))
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// chatConfig represents the settings of a notification channel only registered by tests.
+type chatConfig struct {
+	URL   string `yaml:"url"`
+	Room  string `yaml:"room"`
+	Token string `yaml:"token"`
+}
+
+// beepConfig represents the settings of a notification channel that is only switched on.
+type beepConfig struct {
+	On bool `yaml:"-"`
+}
+
+func init() {
+	RegisterSection(Section{
+		Name: "chat",
+		New:  func() interface{} { return &chatConfig{} },
+		Resolve: func(settings interface{}, r *Resolver) {
+			c := settings.(*chatConfig)
+			r.Required("url", "CHAT_URL", &c.URL)
+			r.Required("room", "CHAT_ROOM", &c.Room)
+			r.Optional("CHAT_TOKEN", &c.Token)
+
+			if c.URL != "" && IsHTTPURL(c.URL) == false {
+				r.Report(fmt.Sprintf("%s: url must be an absolute http or https URL", c.URL), "url")
+			}
+		},
+	})
+
+	RegisterSection(Section{
+		Name: "beep",
+		New:  func() interface{} { return &beepConfig{} },
+		Resolve: func(settings interface{}, r *Resolver) {
+			settings.(*beepConfig).On = true
+		},
+	})
+}
+
+func envChat() func() {
+	return func() {
+		os.Setenv("CHAT_URL", "https://chat.example.com")
+		os.Setenv("CHAT_ROOM", "1")
+		os.Unsetenv("CHAT_TOKEN")
+	}
+}
+
+func TestRegisterSectionTwice(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterSection(Section{Name: "chat"})
+	})
+}
@@ -0,0 +1,93 @@
+package discover
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+var modelRegexp = regexp.MustCompile(`(?i)RTX\s*(\d{4})(\s*TI)?\b`)
+
+// ModelName Derives a catalog model name E.X. 3080 or 2080TI from an NVIDIA product name.
+func ModelName(name string) (string, bool) {
+	match := modelRegexp.FindStringSubmatch(name)
+	if match == nil {
+		return "", false
+	}
+
+	if match[2] != "" {
+		return match[1] + "TI", true
+	}
+
+	return match[1], true
+}
+
+// Region Discovers the models, SKUs, display names and prices sold in a region from NVIDIAs API.
+func Region(ctx context.Context, region config.RegionalConfig, client *rest.Client) (*config.RegionalConfig, error) {
+	info, err := client.GetProducts(ctx, region.Locale, region.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	discovered := config.RegionalConfig{
+		Models:       map[string]config.Model{},
+		Locale:       region.Locale,
+		NvidiaLocale: region.NvidiaLocale,
+		Currency:     region.Currency,
+	}
+
+	for _, product := range info.Products.Product {
+		model, ok := ModelName(product.Name)
+		if ok == false {
+			continue
+		}
+
+		sku := fmt.Sprintf("%v", product.ID)
+
+		if existing, ok := discovered.Models[model]; ok {
+			log.Println(fmt.Sprintf("Skipping %s SKU %s, model %s already found with SKU %s", product.Name, sku, model, *existing.SKU))
+			continue
+		}
+
+		discovered.Models[model] = config.Model{
+			SKU:         &sku,
+			DisplayName: strings.TrimSpace(product.DisplayName),
+			Price:       product.Pricing.FormattedSalePriceWithQuantity,
+			ProductURL:  region.Models[model].ProductURL,
+		}
+	}
+
+	if len(discovered.Models) == 0 {
+		return nil, fmt.Errorf("no models found for %s/%s", region.Locale, region.Currency)
+	}
+
+	return &discovered, nil
+}
+
+// Catalog Discovers every model sold in each of the given regions, regions that fail are reported and omitted.
+func Catalog(ctx context.Context, regions map[string]config.RegionalConfig, client *rest.Client) (config.Catalog, error) {
+	catalog := config.Catalog{}
+	failed := []string{}
+
+	for code, region := range regions {
+		discovered, err := Region(ctx, region, client)
+		if err != nil {
+			log.Println(fmt.Sprintf("Error discovering products for %s: %v", code, err))
+			failed = append(failed, code)
+			continue
+		}
+
+		catalog[code] = *discovered
+	}
+
+	if len(catalog) == 0 {
+		return nil, fmt.Errorf("discovery failed for %v", failed)
+	}
+
+	return catalog, nil
+}
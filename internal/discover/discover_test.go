@@ -0,0 +1,132 @@
+package discover
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+// RoundTripFunc .
+type RoundTripFunc func(req *http.Request) *http.Response
+
+// RoundTrip .
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+// newFixtureClient returns *rest.Client serving recorded NVIDIA API responses from testdata.
+func newFixtureClient(t *testing.T) *rest.Client {
+	fixtures := map[string]string{
+		"https://api-prod.nvidia.com/direct-sales-shop/DR/products/de_de/EUR": "products_de_de_EUR.json",
+	}
+
+	return rest.NewClient(config.APIConfig{}, &http.Client{
+		Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+			name, ok := fixtures[req.URL.String()]
+			if ok == false {
+				return &http.Response{
+					StatusCode: 404,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`Not Found`)),
+					Header:     make(http.Header),
+				}
+			}
+
+			body, err := ioutil.ReadFile(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewReader(body)),
+				Header:     make(http.Header),
+			}
+		}),
+	})
+}
+
+func TestModelName(t *testing.T) {
+	tests := map[string]string{
+		"NVIDIA GEFORCE RTX 3080":       "3080",
+		"NVIDIA GEFORCE RTX 2060 SUPER": "2060",
+		"NVIDIA GEFORCE RTX 2080 Ti":    "2080TI",
+		"NVIDIA GeForce RTX 3060 Ti":    "3060TI",
+	}
+
+	for name, expected := range tests {
+		model, ok := ModelName(name)
+		assert.True(t, ok)
+		assert.Equal(t, expected, model)
+	}
+
+	_, ok := ModelName("NVIDIA SHIELD TV")
+	assert.False(t, ok)
+}
+
+func TestRegion(t *testing.T) {
+	region := config.RegionalConfig{Locale: "de_de", NvidiaLocale: "de-de", Currency: "EUR"}
+
+	result, err := Region(context.Background(), region, newFixtureClient(t))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	assert.Equal(t, []string{"2060", "2080TI", "3070", "3080", "3090"}, sortedModels(result))
+	assert.Equal(t, "5440853700", *result.Models["3080"].SKU)
+	assert.Equal(t, "NVIDIA GEFORCE RTX 3080", result.Models["3080"].DisplayName)
+	assert.Equal(t, "699,00 €", result.Models["3080"].Price)
+	assert.Equal(t, "de-de", result.NvidiaLocale)
+}
+
+func TestCatalogIsLoadable(t *testing.T) {
+	regions := map[string]config.RegionalConfig{
+		"DEU": {Locale: "de_de", NvidiaLocale: "de-de", Currency: "EUR"},
+		"XXX": {Locale: "xx_xx", NvidiaLocale: "xx-xx", Currency: "XXX"},
+	}
+
+	catalog, err := Catalog(context.Background(), regions, newFixtureClient(t))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	data, err := json.Marshal(catalog)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	loaded, err := config.ParseCatalog("discovered", data)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	assert.Equal(t, []string{"DEU"}, keys(loaded))
+	assert.Equal(t, catalog, loaded)
+}
+
+func sortedModels(region *config.RegionalConfig) []string {
+	models := []string{}
+	for _, model := range []string{"2060", "2070", "2080", "2080TI", "3060TI", "3070", "3080", "3090"} {
+		if _, ok := region.Models[model]; ok {
+			models = append(models, model)
+		}
+	}
+
+	return models
+}
+
+func keys(catalog config.Catalog) []string {
+	regions := []string{}
+	for region := range catalog {
+		regions = append(regions, region)
+	}
+
+	return regions
+}
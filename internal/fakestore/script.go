@@ -0,0 +1,170 @@
+package fakestore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+	"gopkg.in/yaml.v3"
+)
+
+// statuses Short names for the inventory statuses a timeline can use.
+var statuses = map[string]string{
+	"in_stock":     rest.StatusInStock,
+	"out_of_stock": rest.StatusOutOfStock,
+	"limited":      rest.StatusLimitedAvailability,
+	"backorder":    rest.StatusBackorder,
+}
+
+// Phase represents a period of a scripted timeline, a zero Duration lasts forever.
+type Phase struct {
+	Duration   time.Duration `yaml:"duration"`
+	Status     string        `yaml:"status"`
+	StatusCode int           `yaml:"status_code"`
+	Price      float64       `yaml:"price"`
+}
+
+// Timeline represents phases played one after another, the last phase is held once every phase has ended.
+type Timeline []Phase
+
+// At Gets the phase active after the store has been running for elapsed.
+func (t Timeline) At(elapsed time.Duration) Phase {
+	if len(t) == 0 {
+		return Phase{}
+	}
+
+	for _, phase := range t {
+		if phase.Duration <= 0 || elapsed < phase.Duration {
+			return phase
+		}
+		elapsed -= phase.Duration
+	}
+
+	return t[len(t)-1]
+}
+
+// Product represents a product sold by the fake store, an empty Locale or Currency matches any.
+type Product struct {
+	SKU         string   `yaml:"sku"`
+	Name        string   `yaml:"name"`
+	DisplayName string   `yaml:"display_name"`
+	Locale      string   `yaml:"locale"`
+	Currency    string   `yaml:"currency"`
+	Timeline    Timeline `yaml:"timeline"`
+}
+
+// Script represents the scripted behaviour of the fake store, E.X.
+//
+//	session:
+//	  - duration: 1m
+//	    status_code: 503
+//	products:
+//	  - sku: 5438481700
+//	    name: NVIDIA GEFORCE RTX 3080
+//	    currency: USD
+//	    timeline:
+//	      - {duration: 30s, status: out_of_stock, price: 699}
+//	      - {duration: 10s, status: in_stock, price: 699}
+//	      - {duration: 1m, status_code: 503}
+//	      - {status: out_of_stock, price: 699}
+type Script struct {
+	Session  Timeline  `yaml:"session"`
+	Products []Product `yaml:"products"`
+}
+
+// ReadScript Reads and validates a YAML fake store script.
+func ReadScript(path string) (*Script, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseScript(data)
+}
+
+// ParseScript Decodes and validates a YAML fake store script, inventory statuses may use their short names E.X. in_stock.
+func ParseScript(data []byte) (*Script, error) {
+	script := Script{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(&script)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	problems := validate("session", script.Session)
+
+	if len(script.Products) == 0 {
+		problems = append(problems, "products: at least one product is required")
+	}
+
+	for i, product := range script.Products {
+		if _, err := strconv.ParseInt(product.SKU, 10, 64); err != nil {
+			problems = append(problems, fmt.Sprintf("products[%d].sku: %s: must be numeric", i, product.SKU))
+		}
+
+		if product.Name == "" {
+			problems = append(problems, fmt.Sprintf("products[%d].name: is required", i))
+		}
+
+		if len(product.Timeline) == 0 {
+			problems = append(problems, fmt.Sprintf("products[%d].timeline: at least one phase is required", i))
+		}
+
+		for j := range product.Timeline {
+			if status, ok := statuses[product.Timeline[j].Status]; ok {
+				script.Products[i].Timeline[j].Status = status
+			}
+		}
+
+		problems = append(problems, validate(fmt.Sprintf("products[%d].timeline", i), script.Products[i].Timeline)...)
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid fake store script:\n%s", strings.Join(problems, "\n"))
+	}
+
+	return &script, nil
+}
+
+// validate Reports phases with negative durations, unknown inventory statuses or invalid HTTP status codes.
+func validate(name string, timeline Timeline) []string {
+	problems := []string{}
+
+	known := map[string]bool{}
+	names := []string{}
+	for short, status := range statuses {
+		known[status] = true
+		names = append(names, short)
+	}
+	sort.Strings(names)
+
+	for i, phase := range timeline {
+		if phase.Status != "" && known[phase.Status] == false {
+			problems = append(problems, fmt.Sprintf("%s[%d].status: %s: must be one of %s or their PRODUCT_INVENTORY_ status", name, i, phase.Status, strings.Join(names, ", ")))
+		}
+
+		if phase.Duration < 0 {
+			problems = append(problems, fmt.Sprintf("%s[%d].duration: must not be negative", name, i))
+		}
+
+		if phase.StatusCode != 0 && http.StatusText(phase.StatusCode) == "" {
+			problems = append(problems, fmt.Sprintf("%s[%d].status_code: %d: unknown HTTP status", name, i, phase.StatusCode))
+		}
+
+		if phase.Price < 0 {
+			problems = append(problems, fmt.Sprintf("%s[%d].price: must not be negative", name, i))
+		}
+	}
+
+	return problems
+}
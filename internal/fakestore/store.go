@@ -0,0 +1,237 @@
+package fakestore
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+// Store represents a stand-in for NVIDIAs store and product APIs that plays back a Script.
+//
+// Point a rest.Client StoreURL and APIURL at it to exercise the whole monitor offline, the
+// timelines start when the store is created.
+type Store struct {
+	script Script
+	clock  func() time.Time
+	start  time.Time
+	router *mux.Router
+
+	mu       sync.Mutex
+	sessions map[string]bool
+	carts    []string
+}
+
+// New Generates a Store playing back a script, clock defaults to time.Now.
+func New(script Script, clock func() time.Time) *Store {
+	if clock == nil {
+		clock = time.Now
+	}
+
+	s := Store{script: script, clock: clock, start: clock(), sessions: map[string]bool{}}
+
+	s.router = mux.NewRouter().StrictSlash(true)
+	s.router.HandleFunc("/store/nvidia/SessionToken", s.sessionToken).Methods("GET")
+	s.router.HandleFunc("/store/nvidia/cart", s.cart).Methods("GET")
+	s.router.HandleFunc("/direct-sales-shop/DR/products/{locale}/{currency}", s.products).Methods("GET")
+	s.router.HandleFunc("/direct-sales-shop/DR/products/{locale}/{currency}/{sku}", s.products).Methods("GET")
+	s.router.HandleFunc("/direct-sales-shop/DR/add-to-cart", s.addToCart).Methods("POST")
+
+	return &s
+}
+
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Println(fmt.Sprintf("Fake store %s %s", r.Method, r.URL))
+	s.router.ServeHTTP(w, r)
+}
+
+// Elapsed Gets how long the scripted timelines have been playing for.
+func (s *Store) Elapsed() time.Duration {
+	return s.clock().Sub(s.start)
+}
+
+// ExpireSessions Invalidates every session token issued so far.
+func (s *Store) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for token := range s.sessions {
+		s.sessions[token] = false
+	}
+}
+
+// Carts Gets the SKUs successfully added to a cart in the order they were added.
+func (s *Store) Carts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.carts...)
+}
+
+func (s *Store) sessionToken(w http.ResponseWriter, r *http.Request) {
+	if s.fail(w, s.script.Session.At(s.Elapsed())) {
+		return
+	}
+
+	s.mu.Lock()
+	token := rest.SessionToken{Value: fmt.Sprintf("fake-session-%d", len(s.sessions)+1)}
+	s.sessions[token.Value] = true
+	s.mu.Unlock()
+
+	s.json(w, http.StatusOK, token)
+}
+
+func (s *Store) products(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	elapsed := s.Elapsed()
+	response := rest.ProductsResponse{}
+
+	for _, product := range s.script.Products {
+		if sku, ok := vars["sku"]; ok && sku != product.SKU {
+			continue
+		}
+
+		if product.matches(vars["locale"], vars["currency"]) == false {
+			continue
+		}
+
+		phase := product.Timeline.At(elapsed)
+
+		// A single SKU lookup fails the same way the real API does when the product is unavailable.
+		if _, ok := vars["sku"]; ok && s.fail(w, phase) {
+			return
+		}
+
+		if phase.StatusCode != 0 && phase.StatusCode != http.StatusOK {
+			continue
+		}
+
+		response.Products.Product = append(response.Products.Product, product.render(phase, vars["currency"]))
+	}
+
+	if _, ok := vars["sku"]; ok && len(response.Products.Product) == 0 {
+		http.Error(w, "product not found", http.StatusNotFound)
+		return
+	}
+
+	s.json(w, http.StatusOK, response)
+}
+
+func (s *Store) addToCart(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	valid := s.sessions[r.Header.Get("nvidia_shop_id")]
+	s.mu.Unlock()
+
+	if valid == false {
+		http.Error(w, "invalid session token", http.StatusUnauthorized)
+		return
+	}
+
+	body := struct {
+		Products []struct {
+			ProductID int64 `json:"productId"`
+			Quantity  int   `json:"quantity"`
+		} `json:"products"`
+	}{}
+
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil || len(body.Products) != 1 {
+		http.Error(w, "expected a single product", http.StatusBadRequest)
+		return
+	}
+
+	sku := strconv.FormatInt(body.Products[0].ProductID, 10)
+	elapsed := s.Elapsed()
+
+	for _, product := range s.script.Products {
+		if product.SKU != sku {
+			continue
+		}
+
+		phase := product.Timeline.At(elapsed)
+		if s.fail(w, phase) {
+			return
+		}
+
+		if phase.Status != rest.StatusInStock && phase.Status != rest.StatusLimitedAvailability {
+			http.Error(w, "product is not in stock", http.StatusConflict)
+			return
+		}
+
+		s.mu.Lock()
+		s.carts = append(s.carts, sku)
+		s.mu.Unlock()
+
+		location := fmt.Sprintf("http://%s/store/nvidia/cart?sku=%s", r.Host, sku)
+		s.json(w, http.StatusOK, rest.AddToCartResponse{URL: location})
+		return
+	}
+
+	http.Error(w, "product not found", http.StatusNotFound)
+}
+
+func (s *Store) cart(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintf(w, "Fake store cart for SKU %s\n", r.URL.Query().Get("sku"))
+}
+
+// fail Responds with the HTTP status of a failing phase, reporting whether it did.
+func (s *Store) fail(w http.ResponseWriter, phase Phase) bool {
+	if phase.StatusCode == 0 || phase.StatusCode == http.StatusOK {
+		return false
+	}
+
+	http.Error(w, http.StatusText(phase.StatusCode), phase.StatusCode)
+	return true
+}
+
+func (s *Store) json(w http.ResponseWriter, code int, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// matches Determines if a product is sold for a locale and currency.
+func (p Product) matches(locale string, currency string) bool {
+	return (p.Locale == "" || p.Locale == locale) && (p.Currency == "" || p.Currency == currency)
+}
+
+// render Generates the API representation of a product during a phase.
+func (p Product) render(phase Phase, currency string) rest.Product {
+	id, _ := strconv.ParseInt(p.SKU, 10, 64)
+	price := rest.Price{Currency: currency, Value: config.MinorUnits(phase.Price)}
+	formatted := fmt.Sprintf("%.2f %s", phase.Price, currency)
+
+	status := phase.Status
+	if status == "" {
+		status = rest.StatusOutOfStock
+	}
+
+	return rest.Product{
+		ID:          id,
+		Name:        p.Name,
+		DisplayName: p.DisplayName,
+		Pricing: rest.Pricing{
+			ListPrice:                      price,
+			SalePriceWithQuantity:          price,
+			FormattedListPrice:             formatted,
+			FormattedSalePriceWithQuantity: formatted,
+		},
+		InventoryStatus: rest.InventoryStatus{
+			Status:           status,
+			ProductIsInStock: strconv.FormatBool(status == rest.StatusInStock || status == rest.StatusLimitedAvailability),
+		},
+	}
+}
@@ -0,0 +1,192 @@
+package fakestore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+// clock represents a manually advanced time source.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestStore(t *testing.T) (*Store, *rest.Client, *clock) {
+	script, err := ReadScript(filepath.Join("testdata", "restock.yaml"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	c := &clock{now: time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)}
+	store := New(*script, c.Now)
+
+	server := httptest.NewServer(store)
+	t.Cleanup(server.Close)
+
+	client := rest.NewClient(config.APIConfig{StoreURL: server.URL, APIURL: server.URL}, server.Client())
+
+	return store, client, c
+}
+
+func TestTimelineAt(t *testing.T) {
+	timeline := Timeline{
+		{Duration: 30 * time.Second, Status: rest.StatusOutOfStock},
+		{Duration: 10 * time.Second, Status: rest.StatusInStock},
+		{Duration: time.Minute, StatusCode: 503},
+	}
+
+	assert.Equal(t, rest.StatusOutOfStock, timeline.At(0).Status)
+	assert.Equal(t, rest.StatusOutOfStock, timeline.At(29*time.Second).Status)
+	assert.Equal(t, rest.StatusInStock, timeline.At(30*time.Second).Status)
+	assert.Equal(t, 503, timeline.At(40*time.Second).StatusCode)
+	assert.Equal(t, 503, timeline.At(time.Hour).StatusCode, "last phase is held")
+	assert.Equal(t, Phase{}, Timeline{}.At(time.Hour))
+}
+
+func TestParseScript(t *testing.T) {
+	script, err := ParseScript([]byte(`products:
+  - sku: 5438481700
+    name: NVIDIA GEFORCE RTX 3080
+    timeline:
+      - {status: limited}
+`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, "5438481700", script.Products[0].SKU)
+	assert.Equal(t, rest.StatusLimitedAvailability, script.Products[0].Timeline[0].Status)
+
+	_, err = ParseScript([]byte(`session:
+  - {duration: -1s}
+products:
+  - sku: RTX3080
+    timeline:
+      - {status_code: 999}
+      - {status: PRODUCT_INVENTORY_BACKORDER}
+      - {status: instock}
+`))
+	if err == nil {
+		t.Fatalf("Expected validation error")
+	}
+	assert.Contains(t, err.Error(), "session[0].duration: must not be negative")
+	assert.Contains(t, err.Error(), "products[0].sku: RTX3080: must be numeric")
+	assert.Contains(t, err.Error(), "products[0].name: is required")
+	assert.Contains(t, err.Error(), "products[0].timeline[0].status_code: 999: unknown HTTP status")
+	assert.Contains(t, err.Error(), "products[0].timeline[2].status: instock: must be one of backorder, in_stock, limited, out_of_stock or their PRODUCT_INVENTORY_ status")
+	assert.NotContains(t, err.Error(), "timeline[1]")
+
+	_, err = ParseScript([]byte(`products: []
+delay: 1`))
+	assert.NotNil(t, err)
+}
+
+func TestStoreSessionToken(t *testing.T) {
+	_, client, c := newTestStore(t)
+
+	_, err := client.GetSessionToken(context.Background())
+	assert.NotNil(t, err, "session API is down for the first 20 seconds")
+
+	c.now = c.now.Add(20 * time.Second)
+	token, err := client.GetSessionToken(context.Background())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, "fake-session-1", token.Value)
+}
+
+func TestStoreProducts(t *testing.T) {
+	_, client, c := newTestStore(t)
+
+	info, err := client.GetSkuInfo(context.Background(), "5438481700", "en_us", "USD")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	product := info.Products.Product[0]
+	assert.Equal(t, int64(5438481700), product.ID)
+	assert.Equal(t, rest.StatusOutOfStock, product.InventoryStatus.Status)
+	assert.Equal(t, int64(69900), product.Pricing.SalePriceWithQuantity.Value)
+	assert.Equal(t, "699.00 USD", product.Pricing.FormattedSalePriceWithQuantity)
+
+	c.now = c.now.Add(35 * time.Second)
+	info, err = client.GetSkuInfo(context.Background(), "5438481700", "en_us", "USD")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, rest.StatusInStock, info.Products.Product[0].InventoryStatus.Status)
+
+	products, err := client.GetProducts(context.Background(), "en_us", "USD")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, 1, len(products.Products.Product))
+
+	products, err = client.GetProducts(context.Background(), "de_de", "EUR")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, 0, len(products.Products.Product))
+
+	c.now = c.now.Add(10 * time.Second)
+	_, err = client.GetSkuInfo(context.Background(), "5438481700", "en_us", "USD")
+	assert.NotNil(t, err, "product API fails for a minute")
+
+	c.now = c.now.Add(time.Minute)
+	info, err = client.GetSkuInfo(context.Background(), "5438481700", "en_us", "USD")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, rest.StatusOutOfStock, info.Products.Product[0].InventoryStatus.Status)
+	assert.Equal(t, int64(74900), info.Products.Product[0].Pricing.SalePriceWithQuantity.Value)
+}
+
+func TestStoreAddToCart(t *testing.T) {
+	store, client, c := newTestStore(t)
+	c.now = c.now.Add(20 * time.Second)
+
+	_, err := client.AddToCheckout(context.Background(), "5438481700", "fake-session-1", "en-us")
+	assert.True(t, isStatus(err, http.StatusUnauthorized), "session token wasn't issued")
+
+	token, err := client.GetSessionToken(context.Background())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = client.AddToCheckout(context.Background(), "5438481700", token.Value, "en-us")
+	assert.True(t, isStatus(err, http.StatusConflict), "product is out of stock")
+
+	c.now = c.now.Add(15 * time.Second)
+	cart, err := client.AddToCheckout(context.Background(), "5438481700", token.Value, "en-us")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Contains(t, cart.URL, "/store/nvidia/cart?sku=5438481700")
+	assert.Equal(t, []string{"5438481700"}, store.Carts())
+
+	res, err := http.Get(cart.URL)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	store.ExpireSessions()
+	_, err = client.AddToCheckout(context.Background(), "5438481700", token.Value, "en-us")
+	assert.True(t, isStatus(err, http.StatusUnauthorized), "session token expired")
+}
+
+func isStatus(err error, code int) bool {
+	var httpErr *rest.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == code
+}
@@ -0,0 +1,146 @@
+package history
+
+import (
+	"time"
+)
+
+// Stats represents the restock patterns of a single watch computed from its recorded polls.
+type Stats struct {
+	Region   string
+	Model    string
+	SKU      string
+	Currency string
+
+	// First and Last are the times of the earliest and latest recorded polls.
+	First  time.Time
+	Last   time.Time
+	Polls  int
+	Errors int
+
+	// Drops number of stock windows, Hours and Weekdays count when each of them started.
+	Drops         int
+	Hours         [24]int
+	Weekdays      [7]int
+	AverageWindow time.Duration
+	LongestWindow time.Duration
+
+	// Prices every sale price change in order, starting with the first price seen.
+	Prices []PricePoint
+}
+
+// PricePoint represents the sale price of a product from a point in time.
+type PricePoint struct {
+	Time  time.Time
+	Price int64
+}
+
+// Summarize Computes Stats for every watch in polls ordered by watch then time as returned by Store.Polls.
+//
+// Hours and weekdays are counted in loc so restocks can be compared to a local schedule.
+func Summarize(polls []Poll, loc *time.Location) []Stats {
+	stats := []Stats{}
+	index := map[string]int{}
+
+	for _, poll := range polls {
+		key := string(watchKey(poll.Region, poll.Model))
+
+		i, ok := index[key]
+		if ok == false {
+			i = len(stats)
+			index[key] = i
+			stats = append(stats, Stats{Region: poll.Region, Model: poll.Model, SKU: poll.SKU, First: poll.Time, Prices: []PricePoint{}})
+		}
+		s := &stats[i]
+
+		s.Polls++
+		s.Last = poll.Time
+
+		if poll.Error != "" {
+			s.Errors++
+			continue
+		}
+
+		if poll.Currency != "" {
+			s.Currency = poll.Currency
+		}
+
+		if poll.Price > 0 && (len(s.Prices) == 0 || s.Prices[len(s.Prices)-1].Price != poll.Price) {
+			s.Prices = append(s.Prices, PricePoint{Time: poll.Time, Price: poll.Price})
+		}
+	}
+
+	total := map[string]time.Duration{}
+
+	for _, window := range Windows(polls) {
+		key := string(watchKey(window.Region, window.Model))
+		s := &stats[index[key]]
+
+		start := window.Start.In(loc)
+		s.Drops++
+		s.Hours[start.Hour()]++
+		s.Weekdays[start.Weekday()]++
+
+		total[key] += window.Duration()
+		if window.Duration() > s.LongestWindow {
+			s.LongestWindow = window.Duration()
+		}
+	}
+
+	for key, i := range index {
+		if stats[i].Drops > 0 {
+			stats[i].AverageWindow = total[key] / time.Duration(stats[i].Drops)
+		}
+	}
+
+	return stats
+}
+
+// DropsPerDay Gets the average number of restocks per day over the recorded period, counting at least one day.
+func (s Stats) DropsPerDay() float64 {
+	days := s.Last.Sub(s.First).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+
+	return float64(s.Drops) / days
+}
+
+// TypicalHour Gets the hour of the day restocks most often started at, false when there were none.
+func (s Stats) TypicalHour() (int, bool) {
+	return mode(s.Hours[:])
+}
+
+// TypicalWeekday Gets the day of the week restocks most often started on, false when there were none.
+func (s Stats) TypicalWeekday() (time.Weekday, bool) {
+	day, ok := mode(s.Weekdays[:])
+	return time.Weekday(day), ok
+}
+
+// PriceRange Gets the lowest and highest sale prices seen, zero when no price was recorded.
+func (s Stats) PriceRange() (int64, int64) {
+	var min, max int64
+
+	for i, point := range s.Prices {
+		if i == 0 || point.Price < min {
+			min = point.Price
+		}
+		if point.Price > max {
+			max = point.Price
+		}
+	}
+
+	return min, max
+}
+
+// mode Gets the index of the largest count, the earliest wins ties.
+func mode(counts []int) (int, bool) {
+	best := -1
+
+	for i, count := range counts {
+		if count > 0 && (best == -1 || count > counts[best]) {
+			best = i
+		}
+	}
+
+	return best, best != -1
+}
@@ -0,0 +1,79 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	// Thursday 2020-09-17, restocks at 13:00 UTC on Thursday and Friday and 09:00 UTC on Saturday.
+	start := time.Date(2020, 9, 17, 0, 0, 0, 0, time.UTC)
+	at := func(day int, hour int, minute int) time.Time {
+		return start.Add(time.Duration(day)*24*time.Hour + time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	polls := []Poll{
+		{Time: at(0, 12, 0), Region: "USA", Model: "3080", SKU: "5438481700", Purchasable: false, Price: 69900, Currency: "USD"},
+		{Time: at(0, 13, 0), Region: "USA", Model: "3080", SKU: "5438481700", Purchasable: true, Price: 69900, Currency: "USD"},
+		{Time: at(0, 13, 2), Region: "USA", Model: "3080", SKU: "5438481700", Purchasable: false, Price: 69900, Currency: "USD"},
+		{Time: at(1, 13, 0), Region: "USA", Model: "3080", SKU: "5438481700", Purchasable: true, Price: 74900, Currency: "USD"},
+		{Time: at(1, 13, 3), Region: "USA", Model: "3080", SKU: "5438481700", Error: "503 Service Unavailable"},
+		{Time: at(1, 13, 4), Region: "USA", Model: "3080", SKU: "5438481700", Purchasable: false, Price: 74900, Currency: "USD"},
+		{Time: at(2, 9, 0), Region: "USA", Model: "3080", SKU: "5438481700", Purchasable: true, Price: 74900, Currency: "USD"},
+		{Time: at(2, 9, 6), Region: "USA", Model: "3080", SKU: "5438481700", Purchasable: false, Price: 69900, Currency: "USD"},
+		{Time: at(0, 12, 0), Region: "USA", Model: "3090", SKU: "5438481600", Purchasable: false, Price: 149900, Currency: "USD"},
+		{Time: at(0, 13, 0), Region: "USA", Model: "3090", SKU: "5438481600", Error: "503 Service Unavailable"},
+	}
+
+	stats := Summarize(polls, time.UTC)
+	assert.Equal(t, 2, len(stats))
+
+	s := stats[0]
+	assert.Equal(t, "3080", s.Model)
+	assert.Equal(t, 8, s.Polls)
+	assert.Equal(t, 1, s.Errors)
+	assert.Equal(t, 3, s.Drops)
+	assert.Equal(t, 2, s.Hours[13])
+	assert.Equal(t, 1, s.Hours[9])
+	assert.Equal(t, 4*time.Minute, s.AverageWindow)
+	assert.Equal(t, 6*time.Minute, s.LongestWindow)
+	assert.InDelta(t, 3/(float64(at(2, 9, 6).Sub(at(0, 12, 0)))/float64(24*time.Hour)), s.DropsPerDay(), 0.0001)
+	assert.Equal(t, "USD", s.Currency)
+
+	hour, ok := s.TypicalHour()
+	assert.True(t, ok)
+	assert.Equal(t, 13, hour)
+
+	day, ok := s.TypicalWeekday()
+	assert.True(t, ok)
+	assert.Equal(t, time.Thursday, day, "ties go to the earliest day")
+
+	assert.Equal(t, []PricePoint{{Time: at(0, 12, 0), Price: 69900}, {Time: at(1, 13, 0), Price: 74900}, {Time: at(2, 9, 6), Price: 69900}}, s.Prices)
+	min, max := s.PriceRange()
+	assert.Equal(t, int64(69900), min)
+	assert.Equal(t, int64(74900), max)
+
+	s = stats[1]
+	assert.Equal(t, "3090", s.Model)
+	assert.Equal(t, 0, s.Drops)
+	assert.Equal(t, 0.0, s.DropsPerDay())
+	_, ok = s.TypicalHour()
+	assert.False(t, ok)
+	assert.Equal(t, time.Duration(0), s.AverageWindow)
+}
+
+func TestSummarizeLocation(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	polls := []Poll{
+		{Time: time.Date(2020, 9, 18, 2, 0, 0, 0, time.UTC), Region: "USA", Model: "3080", Purchasable: true},
+	}
+
+	s := Summarize(polls, loc)[0]
+	hour, _ := s.TypicalHour()
+	day, _ := s.TypicalWeekday()
+	assert.Equal(t, 21, hour)
+	assert.Equal(t, time.Thursday, day)
+	assert.Equal(t, 1.0, s.DropsPerDay(), "less than a day counts as a whole day")
+}
@@ -0,0 +1,311 @@
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buckets holding a nested bucket of records per watch, keyed by the time they were recorded.
+var (
+	pollsBucket         = []byte("polls")
+	notificationsBucket = []byte("notifications")
+)
+
+// ErrLocked is returned when another process held the history file locked for longer than lockTimeout.
+var ErrLocked = errors.New("history file is in use by another process")
+
+// ErrNotFound is returned when querying a history file that hasn't been recorded yet.
+var ErrNotFound = errors.New("no history file")
+
+// lockTimeout Maximum time to wait for another process to finish reading or writing the history file.
+const lockTimeout = time.Second
+
+// Poll represents the result of a single lookup of a watched SKU.
+type Poll struct {
+	Time     time.Time     `json:"time"`
+	Region   string        `json:"region"`
+	Model    string        `json:"model"`
+	SKU      string        `json:"sku"`
+	Status   string        `json:"status,omitempty"`
+	Price    int64         `json:"price,omitempty"`
+	Currency string        `json:"currency,omitempty"`
+	Latency  time.Duration `json:"latency"`
+	Error    string        `json:"error,omitempty"`
+
+	// Purchasable the product could be added to a cart, false whenever the lookup failed.
+	Purchasable bool `json:"purchasable"`
+}
+
+// Notification represents an alert delivered, or attempted, through a single notification channel.
+type Notification struct {
+	Time    time.Time `json:"time"`
+	Region  string    `json:"region"`
+	Model   string    `json:"model"`
+	SKU     string    `json:"sku"`
+	Kind    string    `json:"kind"`
+	Channel string    `json:"channel"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// Query represents a filter on recorded history, empty fields match everything.
+type Query struct {
+	Regions []string
+	Models  []string
+	Since   time.Time
+	Until   time.Time
+}
+
+// Store represents a history file recording every poll and notification of the monitor.
+//
+// The file is only locked for the duration of each read or write so the history and stats
+// commands can query it while the monitor is recording to it.
+type Store struct {
+	path     string
+	readOnly bool
+
+	// mu serializes transactions, the file lock doesn't keep apart two opens from the same process.
+	mu sync.Mutex
+}
+
+// Open Opens or creates a history file for recording.
+func Open(path string) (*Store, error) {
+	return open(path, false)
+}
+
+// OpenReadOnly Opens an existing history file for querying.
+func OpenReadOnly(path string) (*Store, error) {
+	return open(path, true)
+}
+
+func open(path string, readOnly bool) (*Store, error) {
+	s := &Store{path: path, readOnly: readOnly}
+
+	// bbolt creates missing files even when opening read-only, then fails to initialize the empty file.
+	if readOnly {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) || (err == nil && info.Size() == 0) {
+			return nil, fmt.Errorf("%w at %s", ErrNotFound, path)
+		}
+	}
+
+	// Check the file can be opened straight away rather than on the first read or write.
+	err := s.transaction(func(tx *bolt.Tx) error { return nil })
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Close Closes the history file, it is only held open during each read or write.
+func (s *Store) Close() error {
+	return nil
+}
+
+// transaction Opens the history file, runs fn in a read or read-write transaction to match the store and closes the file again.
+func (s *Store) transaction(fn func(tx *bolt.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: lockTimeout, ReadOnly: s.readOnly})
+	if err == bolt.ErrTimeout {
+		return fmt.Errorf("%s: %w", s.path, ErrLocked)
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.readOnly {
+		err = db.View(fn)
+	} else {
+		err = db.Update(fn)
+	}
+
+	closeErr := db.Close()
+	if err != nil {
+		return err
+	}
+
+	return closeErr
+}
+
+// RecordPoll Appends the result of a poll to the history.
+func (s *Store) RecordPoll(poll Poll) error {
+	return s.record(pollsBucket, poll.Region, poll.Model, poll.Time, poll)
+}
+
+// RecordNotification Appends a notification to the history.
+func (s *Store) RecordNotification(notification Notification) error {
+	return s.record(notificationsBucket, notification.Region, notification.Model, notification.Time, notification)
+}
+
+// Polls Gets every recorded poll matching a query ordered by watch then time.
+func (s *Store) Polls(q Query) ([]Poll, error) {
+	polls := []Poll{}
+
+	err := s.each(pollsBucket, q, func(value []byte) error {
+		poll := Poll{}
+		err := json.Unmarshal(value, &poll)
+		if err != nil {
+			return err
+		}
+
+		polls = append(polls, poll)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return polls, nil
+}
+
+// Notifications Gets every recorded notification matching a query ordered by watch then time.
+func (s *Store) Notifications(q Query) ([]Notification, error) {
+	notifications := []Notification{}
+
+	err := s.each(notificationsBucket, q, func(value []byte) error {
+		notification := Notification{}
+		err := json.Unmarshal(value, &notification)
+		if err != nil {
+			return err
+		}
+
+		notifications = append(notifications, notification)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// record Stores a JSON encoded record in the bucket of its watch.
+func (s *Store) record(name []byte, region string, model string, at time.Time, record interface{}) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if s.readOnly {
+		return fmt.Errorf("%s: history file is open read-only", s.path)
+	}
+
+	return s.transaction(func(tx *bolt.Tx) error {
+		root, err := tx.CreateBucketIfNotExists(name)
+		if err != nil {
+			return err
+		}
+
+		bucket, err := root.CreateBucketIfNotExists(watchKey(region, model))
+		if err != nil {
+			return err
+		}
+
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(recordKey(at, seq), value)
+	})
+}
+
+// each Calls fn with every record in a bucket matching a query.
+func (s *Store) each(name []byte, q Query, fn func(value []byte) error) error {
+	return s.transaction(func(tx *bolt.Tx) error {
+		root := tx.Bucket(name)
+		if root == nil {
+			return nil
+		}
+
+		watches := []string{}
+		err := root.ForEach(func(k []byte, v []byte) error {
+			watches = append(watches, string(k))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.Strings(watches)
+
+		for _, watch := range watches {
+			if q.matches(watch) == false {
+				continue
+			}
+
+			c := root.Bucket([]byte(watch)).Cursor()
+
+			start := []byte{}
+			if q.Since.IsZero() == false {
+				start = recordKey(q.Since, 0)
+			}
+
+			for k, v := c.Seek(start); k != nil; k, v = c.Next() {
+				if q.Until.IsZero() == false && keyTime(k).After(q.Until) {
+					break
+				}
+
+				err := fn(v)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// matches Determines if a watch key is selected by the regions and models of a query.
+func (q Query) matches(watch string) bool {
+	region, model := splitWatchKey(watch)
+
+	return (len(q.Regions) == 0 || contains(q.Regions, region)) && (len(q.Models) == 0 || contains(q.Models, model))
+}
+
+func watchKey(region string, model string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", region, model))
+}
+
+func splitWatchKey(key string) (string, string) {
+	parts := strings.SplitN(key, "/", 2)
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+// recordKey Generates a key sorting records by time, the sequence keeps records made at the same instant apart.
+func recordKey(at time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, uint64(at.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+
+	return key
+}
+
+func keyTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[:8])))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
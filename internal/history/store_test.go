@@ -0,0 +1,142 @@
+package history
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func newTestStore(t *testing.T) (*Store, string) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return store, path
+}
+
+func TestStorePolls(t *testing.T) {
+	store, path := newTestStore(t)
+	start := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		at := start.Add(time.Duration(i) * time.Minute)
+
+		assert.Nil(t, store.RecordPoll(Poll{Time: at, Region: "USA", Model: "3080", SKU: "5438481700", Latency: 120 * time.Millisecond}))
+		assert.Nil(t, store.RecordPoll(Poll{Time: at, Region: "DEU", Model: "3080", SKU: "5438792300", Error: "503 Service Unavailable"}))
+		assert.Nil(t, store.RecordPoll(Poll{Time: at, Region: "USA", Model: "3090", SKU: "5438481600"}))
+	}
+
+	// Polls recorded at the same instant are all kept.
+	assert.Nil(t, store.RecordPoll(Poll{Time: start, Region: "USA", Model: "3080", SKU: "5438481700"}))
+	assert.Nil(t, store.Close())
+
+	store, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer store.Close()
+
+	polls, err := store.Polls(Query{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, 10, len(polls))
+	assert.Equal(t, "DEU", polls[0].Region, "ordered by watch")
+	assert.Equal(t, "503 Service Unavailable", polls[0].Error)
+
+	polls, _ = store.Polls(Query{Regions: []string{"USA"}, Models: []string{"3080"}})
+	assert.Equal(t, 4, len(polls))
+	assert.True(t, polls[0].Time.Equal(start))
+	assert.Equal(t, 120*time.Millisecond, polls[0].Latency)
+
+	polls, _ = store.Polls(Query{Models: []string{"3080"}, Since: start.Add(time.Minute), Until: start.Add(time.Minute)})
+	assert.Equal(t, 2, len(polls))
+	assert.True(t, polls[0].Time.Equal(start.Add(time.Minute)))
+}
+
+func TestStoreNotifications(t *testing.T) {
+	store, _ := newTestStore(t)
+	defer store.Close()
+
+	notifications, err := store.Notifications(Query{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, 0, len(notifications))
+
+	at := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	assert.Nil(t, store.RecordNotification(Notification{Time: at, Region: "USA", Model: "3080", SKU: "5438481700", Kind: "in_stock", Channel: "discord"}))
+	assert.Nil(t, store.RecordNotification(Notification{Time: at, Region: "USA", Model: "3080", SKU: "5438481700", Kind: "in_stock", Channel: "sms", Error: "timeout"}))
+
+	notifications, _ = store.Notifications(Query{Regions: []string{"USA"}})
+	assert.Equal(t, 2, len(notifications))
+	assert.Equal(t, "discord", notifications[0].Channel)
+	assert.Equal(t, "timeout", notifications[1].Error)
+}
+
+func TestStoreConcurrentReader(t *testing.T) {
+	store, path := newTestStore(t)
+	defer store.Close()
+
+	at := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	assert.Nil(t, store.RecordPoll(Poll{Time: at, Region: "USA", Model: "3080"}))
+
+	// A running monitor doesn't keep the history commands out.
+	reader, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer reader.Close()
+
+	assert.Nil(t, store.RecordPoll(Poll{Time: at.Add(time.Minute), Region: "USA", Model: "3080"}))
+
+	polls, err := reader.Polls(Query{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, 2, len(polls))
+	assert.NotNil(t, reader.RecordPoll(Poll{Time: at}))
+}
+
+func TestStoreLocked(t *testing.T) {
+	_, path := newTestStore(t)
+
+	// Another process holding the file past the timeout.
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer db.Close()
+
+	_, err = OpenReadOnly(path)
+	assert.True(t, errors.Is(err, ErrLocked))
+}
+
+func TestStoreNotFound(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	_, err := OpenReadOnly(path)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Equal(t, "no history file at "+path, err.Error())
+
+	_, statErr := os.Stat(path)
+	assert.True(t, os.IsNotExist(statErr), "no empty file is left behind")
+
+	// Left behind by earlier versions.
+	err = ioutil.WriteFile(path, nil, 0600)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	_, err = OpenReadOnly(path)
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,86 @@
+package history
+
+import (
+	"fmt"
+	"time"
+)
+
+// Window represents a continuous period a watched SKU could be added to a cart.
+type Window struct {
+	Region   string
+	Model    string
+	SKU      string
+	Start    time.Time
+	End      time.Time
+	Price    int64
+	Currency string
+
+	// Open the product was still purchasable at the last recorded poll, End is that poll.
+	Open bool
+}
+
+// Duration Gets how long the product was purchasable for.
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// Windows Finds every stock window in polls ordered by watch then time as returned by Store.Polls.
+//
+// A window starts at the first purchasable poll and ends at the next successful poll that isn't,
+// failed polls don't end a window as nothing is known about the inventory at the time.
+func Windows(polls []Poll) []Window {
+	windows := []Window{}
+
+	var current *Window
+	var last Poll
+
+	closeOpen := func() {
+		if current != nil {
+			current.End = last.Time
+			current.Open = true
+			windows = append(windows, *current)
+			current = nil
+		}
+	}
+
+	for _, poll := range polls {
+		if current != nil && (poll.Region != current.Region || poll.Model != current.Model) {
+			closeOpen()
+		}
+
+		if poll.Error != "" {
+			continue
+		}
+
+		switch {
+		case poll.Purchasable && current == nil:
+			current = &Window{
+				Region:   poll.Region,
+				Model:    poll.Model,
+				SKU:      poll.SKU,
+				Start:    poll.Time,
+				Price:    poll.Price,
+				Currency: poll.Currency,
+			}
+		case poll.Purchasable == false && current != nil:
+			current.End = poll.Time
+			windows = append(windows, *current)
+			current = nil
+		}
+
+		last = poll
+	}
+
+	closeOpen()
+
+	return windows
+}
+
+// FormatPrice Formats a price in the minor unit of its currency E.X. 69900 USD as 699.00 USD.
+func FormatPrice(price int64, currency string) string {
+	if price <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d.%02d %s", price/100, price%100, currency)
+}
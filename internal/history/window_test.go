@@ -0,0 +1,49 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindows(t *testing.T) {
+	start := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	polls := []Poll{
+		{Time: at(0), Region: "DEU", Model: "3080", Purchasable: true, Price: 69900, Currency: "EUR"},
+		{Time: at(10), Region: "DEU", Model: "3080", Purchasable: true, Price: 69900, Currency: "EUR"},
+		{Time: at(0), Region: "USA", Model: "3080", Purchasable: false},
+		{Time: at(10), Region: "USA", Model: "3080", Purchasable: true, Price: 69900, Currency: "USD"},
+		{Time: at(20), Region: "USA", Model: "3080", Error: "503 Service Unavailable"},
+		{Time: at(30), Region: "USA", Model: "3080", Purchasable: true, Price: 74900, Currency: "USD"},
+		{Time: at(40), Region: "USA", Model: "3080", Purchasable: false},
+		{Time: at(50), Region: "USA", Model: "3080", Purchasable: true, Price: 74900, Currency: "USD"},
+		{Time: at(60), Region: "USA", Model: "3080", Error: "503 Service Unavailable"},
+	}
+
+	windows := Windows(polls)
+	assert.Equal(t, 3, len(windows))
+
+	assert.Equal(t, "DEU", windows[0].Region)
+	assert.True(t, windows[0].Open, "still in stock when the watch changes")
+	assert.Equal(t, 10*time.Second, windows[0].Duration())
+
+	assert.Equal(t, "USA", windows[1].Region)
+	assert.False(t, windows[1].Open)
+	assert.True(t, windows[1].Start.Equal(at(10)))
+	assert.Equal(t, 30*time.Second, windows[1].Duration(), "failed polls don't end a window")
+	assert.Equal(t, int64(69900), windows[1].Price)
+
+	assert.True(t, windows[2].Open)
+	assert.Equal(t, time.Duration(0), windows[2].Duration(), "open windows end at the last successful poll")
+}
+
+func TestFormatPrice(t *testing.T) {
+	assert.Equal(t, "699.00 USD", FormatPrice(69900, "USD"))
+	assert.Equal(t, "749.99 EUR", FormatPrice(74999, "EUR"))
+	assert.Equal(t, "", FormatPrice(0, "EUR"))
+}
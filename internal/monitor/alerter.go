@@ -0,0 +1,144 @@
+package monitor
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+// Alerter decides which inventory observations of a single watched SKU trigger alerts.
+//
+// Once an alert for a purchasable state is sent, further purchasable alerts are suppressed
+// until the Cooldown passes. Seeing the product out of stock clears the cooldown so a restock
+// alerts straight away, and a product that is still purchasable when the cooldown runs out is
+// alerted on again as long as the change into its current state matches the Filter. A zero
+// Cooldown disables suppression.
+//
+// Purchasable alerts for a product priced above MaxPrice are held back until the price drops
+// to MaxPrice or below while it is still purchasable. A zero MaxPrice disables the limit.
+// Changes in sale price are only reported when PriceChanges is set.
+type Alerter struct {
+	Name         string
+	Filter       Filter
+	Cooldown     time.Duration
+	MaxPrice     int64
+	PriceChanges bool
+
+	tracker Tracker
+	prices  PriceTracker
+	until   time.Time
+	held    bool
+
+	// entered is the last change in state, re-alerts after the cooldown only repeat states the Filter alerts on.
+	entered Transition
+}
+
+// Observe Records an inventory status and sale price in minor units, reporting the transition and whether it should be alerted on.
+func (a *Alerter) Observe(status string, price int64, now time.Time) (Transition, bool) {
+	transition, ok := a.observe(status, now)
+
+	if transition.To.Purchasable() == false {
+		a.held = false
+		return transition, ok
+	}
+
+	if ok == false && a.held == false {
+		return transition, false
+	}
+
+	if a.MaxPrice > 0 && price > a.MaxPrice {
+		if a.held == false {
+			log.Println(fmt.Sprintf("[%s] Price %d above max price %d, holding %s alert", a.Name, price, a.MaxPrice, transition))
+		}
+		a.held = true
+		return transition, false
+	}
+
+	if a.held == true {
+		log.Println(fmt.Sprintf("[%s] Price %d within max price %d, alerting", a.Name, price, a.MaxPrice))
+		a.held = false
+	}
+
+	return transition, true
+}
+
+// ObservePrice Records the pricing of the product returning the previous pricing if a sale price change should be alerted on.
+func (a *Alerter) ObservePrice(pricing rest.Pricing) (rest.Pricing, bool) {
+	previous, changed := a.prices.Observe(pricing)
+	if changed {
+		log.Println(fmt.Sprintf("[%s] Price changed %s -> %s", a.Name, previous.FormattedSalePriceWithQuantity, pricing.FormattedSalePriceWithQuantity))
+	}
+
+	return previous, changed && a.PriceChanges
+}
+
+// PriceFailed Restores the pricing from before an undelivered price change alert so it is retried on the next poll.
+func (a *Alerter) PriceFailed(previous rest.Pricing) {
+	a.prices.Rollback(previous)
+}
+
+// observe Applies the transition filter and cooldown to an inventory status.
+func (a *Alerter) observe(status string, now time.Time) (Transition, bool) {
+	transition, changed := a.tracker.Observe(status)
+	if changed {
+		log.Println(fmt.Sprintf("[%s] Status changed %s", a.Name, transition))
+	}
+
+	if transition.To == OutOfStock && a.until.IsZero() == false {
+		a.until = time.Time{}
+		log.Println(fmt.Sprintf("[%s] Out of stock, cooldown cleared", a.Name))
+	}
+
+	if changed {
+		a.entered = transition
+
+		if a.Filter.Match(transition) == false {
+			return transition, false
+		}
+
+		if transition.To.Purchasable() && now.Before(a.until) {
+			log.Println(fmt.Sprintf("[%s] Cooldown active for %s, suppressing %s alert", a.Name, a.until.Sub(now).Round(time.Second), transition))
+			return transition, false
+		}
+
+		return transition, true
+	}
+
+	if transition.To.Purchasable() && a.until.IsZero() == false && now.Before(a.until) == false {
+		if a.Filter.Match(a.entered) == false {
+			return transition, false
+		}
+
+		log.Println(fmt.Sprintf("[%s] Cooldown expired while still %s, alerting again", a.Name, transition.To))
+		return transition, true
+	}
+
+	return transition, false
+}
+
+// Sent Records an alert delivered by at least one notifier, starting the cooldown when the product can be purchased.
+func (a *Alerter) Sent(transition Transition, now time.Time) {
+	if a.Cooldown <= 0 || transition.To.Purchasable() == false {
+		return
+	}
+
+	a.until = now.Add(a.Cooldown)
+	log.Println(fmt.Sprintf("[%s] Cooldown started, suppressing alerts until %s", a.Name, a.until.Format(time.Stamp)))
+}
+
+// Failed Restores the state from before an alert no notifier delivered so it is retried on the next poll.
+func (a *Alerter) Failed(transition Transition) {
+	a.tracker.Rollback(transition)
+	a.held = transition.To.Purchasable()
+}
+
+// Remaining Gets how long alerts remain suppressed for.
+func (a *Alerter) Remaining(now time.Time) time.Duration {
+	if now.Before(a.until) {
+		return a.until.Sub(now)
+	}
+
+	return 0
+}
@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlerterCooldown(t *testing.T) {
+	filter, _ := ParseFilter(nil)
+	alerter := Alerter{Name: "USA/3080", Filter: filter, Cooldown: 10 * time.Minute}
+	start := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+
+	_, ok := alerter.Observe(rest.StatusOutOfStock, 0, start)
+	assert.False(t, ok)
+
+	transition, ok := alerter.Observe(rest.StatusInStock, 0, start.Add(time.Minute))
+	assert.True(t, ok)
+	alerter.Sent(transition, start.Add(time.Minute))
+	assert.Equal(t, 10*time.Minute, alerter.Remaining(start.Add(time.Minute)))
+
+	// Still in stock, nothing new to report.
+	_, ok = alerter.Observe(rest.StatusInStock, 0, start.Add(2*time.Minute))
+	assert.False(t, ok)
+
+	// API glitch without going out of stock is suppressed.
+	alerter.Observe("", 0, start.Add(3*time.Minute))
+	_, ok = alerter.Observe(rest.StatusInStock, 0, start.Add(4*time.Minute))
+	assert.False(t, ok)
+
+	// Cooldown expiring while still in stock alerts again.
+	transition, ok = alerter.Observe(rest.StatusInStock, 0, start.Add(12*time.Minute))
+	assert.True(t, ok)
+	alerter.Sent(transition, start.Add(12*time.Minute))
+
+	// Going out of stock clears the cooldown and the restock alerts straight away.
+	_, ok = alerter.Observe(rest.StatusOutOfStock, 0, start.Add(13*time.Minute))
+	assert.False(t, ok)
+	assert.Equal(t, time.Duration(0), alerter.Remaining(start.Add(13*time.Minute)))
+
+	_, ok = alerter.Observe(rest.StatusInStock, 0, start.Add(14*time.Minute))
+	assert.True(t, ok)
+}
+
+func TestAlerterCooldownFilter(t *testing.T) {
+	filter, _ := ParseFilter([]string{"out_of_stock->in_stock"})
+	alerter := Alerter{Name: "USA/3080", Filter: filter, Cooldown: 10 * time.Minute}
+	start := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+
+	alerter.Observe(rest.StatusOutOfStock, 0, start)
+	transition, ok := alerter.Observe(rest.StatusInStock, 0, start.Add(time.Minute))
+	assert.True(t, ok)
+	alerter.Sent(transition, start.Add(time.Minute))
+
+	// Limited availability isn't a configured transition, neither when it happens nor once the cooldown expires.
+	_, ok = alerter.Observe(rest.StatusLimitedAvailability, 0, start.Add(2*time.Minute))
+	assert.False(t, ok)
+
+	_, ok = alerter.Observe(rest.StatusLimitedAvailability, 0, start.Add(12*time.Minute))
+	assert.False(t, ok)
+
+	// Back in stock without passing through out of stock doesn't match either.
+	_, ok = alerter.Observe(rest.StatusInStock, 0, start.Add(13*time.Minute))
+	assert.False(t, ok)
+}
+
+func TestAlerterWithoutCooldown(t *testing.T) {
+	filter, _ := ParseFilter(nil)
+	alerter := Alerter{Name: "USA/3080", Filter: filter}
+	now := time.Now()
+
+	transition, ok := alerter.Observe(rest.StatusInStock, 0, now)
+	assert.True(t, ok)
+	alerter.Sent(transition, now)
+
+	_, ok = alerter.Observe(rest.StatusInStock, 0, now.Add(time.Hour))
+	assert.False(t, ok)
+
+	alerter.Observe(rest.StatusOutOfStock, 0, now)
+	_, ok = alerter.Observe(rest.StatusInStock, 0, now)
+	assert.True(t, ok)
+}
+
+func TestAlerterFailed(t *testing.T) {
+	filter, _ := ParseFilter(nil)
+	alerter := Alerter{Name: "USA/3080", Filter: filter}
+	now := time.Now()
+
+	transition, ok := alerter.Observe(rest.StatusInStock, 0, now)
+	assert.True(t, ok)
+	alerter.Failed(transition)
+
+	_, ok = alerter.Observe(rest.StatusInStock, 0, now)
+	assert.True(t, ok)
+}
+
+func TestAlerterMaxPrice(t *testing.T) {
+	filter, _ := ParseFilter(nil)
+	alerter := Alerter{Name: "USA/3080", Filter: filter, MaxPrice: 69900}
+	now := time.Now()
+
+	_, ok := alerter.Observe(rest.StatusInStock, 74900, now)
+	assert.False(t, ok, "above max price is held")
+
+	_, ok = alerter.Observe(rest.StatusInStock, 74900, now)
+	assert.False(t, ok)
+
+	transition, ok := alerter.Observe(rest.StatusInStock, 69900, now)
+	assert.True(t, ok, "price dropped to max price while in stock")
+	alerter.Sent(transition, now)
+
+	_, ok = alerter.Observe(rest.StatusInStock, 64900, now)
+	assert.False(t, ok)
+
+	// Going out of stock drops a held alert.
+	alerter.Observe(rest.StatusOutOfStock, 74900, now)
+	_, ok = alerter.Observe(rest.StatusInStock, 74900, now)
+	assert.False(t, ok)
+	alerter.Observe(rest.StatusOutOfStock, 64900, now)
+	_, ok = alerter.Observe(rest.StatusOutOfStock, 64900, now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,302 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/alert"
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/history"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+// Poller checks the inventory of a single watched SKU and delivers its alerts.
+type Poller struct {
+	Client    *rest.Client
+	Watch     config.Watch
+	Alerter   *Alerter
+	Notifiers []alert.Notifier
+
+	// Sessions adds purchasable products to a cart, without it alerts link to the product page.
+	Sessions *rest.SessionManager
+
+	// History records every poll and notification when set.
+	History *history.Store
+
+	// Remote only sends notifications, otherwise purchasable products are opened with Open.
+	Remote bool
+	Open   func(url string) error
+
+	// pending is the last stock alert some notifiers failed to deliver.
+	pending *pendingAlert
+}
+
+// pendingAlert represents a stock alert that is retried through the notifiers that failed it while the product stays in the state it alerted on.
+type pendingAlert struct {
+	transition Transition
+	event      alert.StockEvent
+	notifiers  []alert.Notifier
+}
+
+// NotifyTimeout Maximum time given to each notifier to deliver an event, notifiers with a retry policy that needs longer get its deadline instead.
+const NotifyTimeout = 10 * time.Second
+
+// Poll Looks up the watched SKU once, alerting on price and inventory changes.
+//
+// Cancelling ctx abandons requests to NVIDIA, but alerts for changes that were already seen are
+// still delivered so they aren't lost during shutdown.
+func (p *Poller) Poll(ctx context.Context, now time.Time) error {
+	watch := p.Watch
+
+	started := time.Now()
+	info, err := p.Client.GetSkuInfo(ctx, watch.SKU, watch.Locale, watch.Currency)
+	latency := time.Since(started)
+
+	if err != nil {
+		p.recordPoll(now, latency, nil, err)
+
+		var decodeErr *rest.DecodeError
+
+		switch {
+		case errors.Is(err, rest.ErrRateLimited):
+			log.Println(fmt.Sprintf("[%s] Rate limited by NVIDIA slowing down...", watch))
+		case errors.Is(err, rest.ErrServerError):
+			log.Println(fmt.Sprintf("[%s] NVIDIA API unavailable retrying...", watch))
+		case errors.As(err, &decodeErr):
+			log.Println(fmt.Sprintf("[%s] Unexpected product information from NVIDIA retrying...", watch))
+		}
+
+		return err
+	}
+
+	// HACK: Resolves https://github.com/ianmarmour/nvidia-clerk/issues/85
+	if len(info.Products.Product) < 1 {
+		log.Printf("[%s] Error attempting to get product information retrying...\n", watch)
+		err = fmt.Errorf("no product information for %s", watch.SKU)
+		p.recordPoll(now, latency, nil, err)
+		return err
+	}
+
+	log.Println(fmt.Sprintf("[%s] Product ID: %v", watch, info.Products.Product[0].ID))
+	log.Println(fmt.Sprintf("[%s] Product Name: %s", watch, info.Products.Product[0].Name))
+	log.Println(fmt.Sprintf("[%s] Product Locale: %s", watch, watch.Locale))
+	log.Println(fmt.Sprintf("[%s] Product Status: %s\n", watch, info.Products.Product[0].InventoryStatus.Status))
+
+	product := info.Products.Product[0]
+	p.recordPoll(now, latency, &product, nil)
+
+	previous, changed := p.Alerter.ObservePrice(product.Pricing)
+	if changed {
+		_, err = p.notify(ctx, alert.NewPriceEvent(watch, product, previous, watch.ProductURL), p.Notifiers)
+		if err != nil {
+			log.Println(fmt.Sprintf("[%s] Error attempting to send price notification retrying...", watch))
+			p.Alerter.PriceFailed(previous)
+		}
+	}
+
+	transition, ok := p.Alerter.Observe(product.InventoryStatus.Status, product.Pricing.SalePriceWithQuantity.Value, now)
+	if ok == false {
+		return p.retry(ctx, transition)
+	}
+	p.pending = nil
+
+	cartURL := watch.ProductURL
+	if transition.To.Purchasable() {
+		cartURL = p.checkout(ctx, watch.ProductURL)
+	}
+
+	event := alert.NewStockEvent(watch, product, transition.PreviousStatus, cartURL)
+
+	failed, err := p.notify(ctx, event, p.Notifiers)
+	if err != nil && len(failed) == len(p.Notifiers) {
+		log.Println(fmt.Sprintf("[%s] Error attempting to send notification retrying...", watch))
+		p.Alerter.Failed(transition)
+		return err
+	}
+	p.Alerter.Sent(transition, now)
+
+	if err != nil {
+		log.Println(fmt.Sprintf("[%s] Error attempting to send %d of %d notifications retrying them...", watch, len(failed), len(p.Notifiers)))
+		p.pending = &pendingAlert{transition: transition, event: event, notifiers: failed}
+	}
+
+	if p.Remote != true && p.Open != nil && transition.To.Purchasable() {
+		err = p.Open(cartURL)
+		if err != nil {
+			log.Println(fmt.Sprintf("[%s] Error attempting to open browser: %v", watch, err))
+		}
+	}
+
+	return err
+}
+
+// retry Delivers the pending alert through the notifiers that failed it, dropping it once the product has left the state it alerted on.
+func (p *Poller) retry(ctx context.Context, current Transition) error {
+	if p.pending == nil {
+		return nil
+	}
+
+	if current.To != p.pending.transition.To {
+		log.Println(fmt.Sprintf("[%s] No longer %s, dropping undelivered notifications", p.Watch, p.pending.transition.To))
+		p.pending = nil
+		return nil
+	}
+
+	failed, err := p.notify(ctx, p.pending.event, p.pending.notifiers)
+	if err != nil {
+		log.Println(fmt.Sprintf("[%s] Error attempting to send %d notifications retrying them...", p.Watch, len(failed)))
+		p.pending.notifiers = failed
+		return err
+	}
+	p.pending = nil
+
+	return nil
+}
+
+// checkout Adds the watched SKU to a cart returning its URL, or fallback when that isn't possible.
+func (p *Poller) checkout(ctx context.Context, fallback string) string {
+	if p.Sessions == nil {
+		return fallback
+	}
+
+	cart, err := p.Sessions.AddToCheckout(ctx, p.Watch.SKU, p.Watch.NvidiaLocale)
+	if err != nil {
+		log.Println(fmt.Sprintf("[%s] Error adding product to cart using the product page instead: %v", p.Watch, err))
+		return fallback
+	}
+
+	if strings.HasPrefix(cart.URL, "http") == false {
+		log.Println(fmt.Sprintf("[%s] NVIDIA returned no cart URL using the product page instead", p.Watch))
+		return fallback
+	}
+
+	log.Println(fmt.Sprintf("[%s] Added to cart: %s", p.Watch, cart.URL))
+
+	return cart.URL
+}
+
+// notify Delivers an event through notifiers at once, returning the ones that failed and the last error so they can be retried.
+//
+// Each notifier has its own deadline so a slow channel doesn't use up the time of the others.
+func (p *Poller) notify(ctx context.Context, event alert.StockEvent, notifiers []alert.Notifier) ([]alert.Notifier, error) {
+	if p.Remote != true {
+		event.CartURL = "Checkout avaliable on system running this program"
+	}
+
+	errs := make([]error, len(notifiers))
+
+	var wg sync.WaitGroup
+	for i, notifier := range notifiers {
+		wg.Add(1)
+		go func(i int, notifier alert.Notifier) {
+			defer wg.Done()
+
+			notifyCtx, cancel := context.WithTimeout(detached{ctx}, notifyDeadline(notifier))
+			defer cancel()
+
+			errs[i] = notifier.Notify(notifyCtx, event)
+			if errs[i] != nil {
+				log.Println(fmt.Sprintf("Error sending %s notification, retrying...", notifier.Name()))
+			}
+
+			p.recordNotification(event, notifier.Name(), errs[i])
+		}(i, notifier)
+	}
+	wg.Wait()
+
+	var err error
+	failed := []alert.Notifier{}
+	for i, notifyErr := range errs {
+		if notifyErr != nil {
+			failed = append(failed, notifiers[i])
+			err = notifyErr
+		}
+	}
+
+	return failed, err
+}
+
+// notifyDeadline Gets the time a notifier is given to deliver an event, NotifyTimeout unless its retry policy needs longer.
+func notifyDeadline(notifier alert.Notifier) time.Duration {
+	if n, ok := notifier.(alert.DeadlineNotifier); ok && n.Deadline() > NotifyTimeout {
+		return n.Deadline()
+	}
+
+	return NotifyTimeout
+}
+
+// recordPoll Adds the result of a poll to the history, failing to record is logged rather than interrupting monitoring.
+func (p *Poller) recordPoll(now time.Time, latency time.Duration, product *rest.Product, err error) {
+	if p.History == nil {
+		return
+	}
+
+	poll := history.Poll{
+		Time:     now,
+		Region:   p.Watch.Region,
+		Model:    p.Watch.Model,
+		SKU:      p.Watch.SKU,
+		Currency: p.Watch.Currency,
+		Latency:  latency,
+	}
+
+	if err != nil {
+		poll.Error = err.Error()
+	} else {
+		poll.Status = product.InventoryStatus.Status
+		poll.Price = product.Pricing.SalePriceWithQuantity.Value
+		poll.Purchasable = ParseState(poll.Status).Purchasable()
+	}
+
+	recordErr := p.History.RecordPoll(poll)
+	if recordErr != nil {
+		log.Println(fmt.Sprintf("[%s] Error recording poll history: %v", p.Watch, recordErr))
+	}
+}
+
+// recordNotification Adds a notification sent through a channel to the history.
+func (p *Poller) recordNotification(event alert.StockEvent, channel string, err error) {
+	if p.History == nil {
+		return
+	}
+
+	notification := history.Notification{
+		Time:    event.Timestamp,
+		Region:  p.Watch.Region,
+		Model:   p.Watch.Model,
+		SKU:     p.Watch.SKU,
+		Kind:    event.Kind(),
+		Channel: channel,
+	}
+
+	if err != nil {
+		notification.Error = err.Error()
+	}
+
+	recordErr := p.History.RecordNotification(notification)
+	if recordErr != nil {
+		log.Println(fmt.Sprintf("[%s] Error recording notification history: %v", p.Watch, recordErr))
+	}
+}
+
+// detached represents a context with the values of its parent but none of its cancellation.
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
@@ -0,0 +1,440 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/alert"
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/fakestore"
+	"github.com/ianmarmour/nvidia-clerk/internal/history"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingNotifier records every event it is asked to deliver.
+type recordingNotifier struct {
+	events []alert.StockEvent
+	errs   []error
+}
+
+func (n *recordingNotifier) Name() string {
+	return "recording"
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event alert.StockEvent) error {
+	n.events = append(n.events, event)
+	n.errs = append(n.errs, ctx.Err())
+	return nil
+}
+
+// failingNotifier fails to deliver the first failures events it is asked to, recording the ones it delivers.
+type failingNotifier struct {
+	failures int
+	calls    int
+	events   []alert.StockEvent
+}
+
+func (n *failingNotifier) Name() string {
+	return "failing"
+}
+
+func (n *failingNotifier) Notify(ctx context.Context, event alert.StockEvent) error {
+	n.calls++
+	if n.calls <= n.failures {
+		return errors.New("channel unavailable")
+	}
+
+	n.events = append(n.events, event)
+	return nil
+}
+
+// blockingNotifier records the deadline it is given, closing closes and then waiting for waits when they are set.
+type blockingNotifier struct {
+	deadline time.Duration
+	closes   chan struct{}
+	waits    chan struct{}
+	given    time.Duration
+}
+
+func (n *blockingNotifier) Name() string {
+	return "blocking"
+}
+
+func (n *blockingNotifier) Notify(ctx context.Context, event alert.StockEvent) error {
+	deadline, _ := ctx.Deadline()
+	n.given = time.Until(deadline).Round(time.Second)
+
+	if n.closes != nil {
+		close(n.closes)
+	}
+
+	if n.waits == nil {
+		return nil
+	}
+
+	select {
+	case <-n.waits:
+		return nil
+	case <-time.After(time.Second):
+		return errors.New("notifiers weren't delivered to at the same time")
+	}
+}
+
+func (n *blockingNotifier) Deadline() time.Duration {
+	return n.deadline
+}
+
+// cancellingTransport cancels a context as soon as a response has been received.
+type cancellingTransport struct {
+	cancel context.CancelFunc
+}
+
+func (t cancellingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r, err := http.DefaultTransport.RoundTrip(req)
+	t.cancel()
+	return r, err
+}
+
+// TestPollerFakeStore Runs the poll, notify and add to cart flow against the fake store restock script.
+func TestPollerFakeStore(t *testing.T) {
+	script, err := fakestore.ReadScript(filepath.Join("..", "fakestore", "testdata", "restock.yaml"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	now := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	store := fakestore.New(*script, func() time.Time { return now })
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	client := rest.NewClient(config.APIConfig{StoreURL: server.URL, APIURL: server.URL}, server.Client())
+	notifier := &recordingNotifier{}
+	opened := []string{}
+	filter, _ := ParseFilter(nil)
+
+	recorded, err := history.Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer recorded.Close()
+
+	watch := config.Watch{Region: "USA", Model: "3080", SKU: "5438481700", Locale: "en_us", NvidiaLocale: "en-us", Currency: "USD", ProductURL: "https://www.nvidia.com/en-us/geforce/graphics-cards/30-series/rtx-3080/"}
+	poller := Poller{
+		Client:    client,
+		Watch:     watch,
+		Alerter:   &Alerter{Name: watch.String(), Filter: filter, PriceChanges: true},
+		Notifiers: []alert.Notifier{notifier},
+		Sessions:  rest.NewSessionManager(client),
+		History:   recorded,
+		Open: func(url string) error {
+			opened = append(opened, url)
+			return nil
+		},
+	}
+
+	failures := 0
+	for elapsed := time.Duration(0); elapsed < 2*time.Minute; elapsed += 5 * time.Second {
+		now = time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC).Add(elapsed)
+
+		if poller.Poll(context.Background(), now) != nil {
+			failures++
+		}
+	}
+
+	assert.Equal(t, 12, failures, "product API fails for a minute")
+	assert.Equal(t, 2, len(notifier.events))
+
+	assert.Equal(t, alert.KindInStock, notifier.events[0].Kind())
+	assert.Equal(t, rest.StatusOutOfStock, notifier.events[0].PreviousStatus)
+	assert.Equal(t, "699.00 USD", notifier.events[0].Price)
+
+	assert.Equal(t, alert.KindPriceIncrease, notifier.events[1].Kind())
+	assert.Equal(t, "699.00 USD", notifier.events[1].PreviousPrice)
+	assert.Equal(t, "749.00 USD", notifier.events[1].Price)
+
+	assert.Equal(t, 1, len(opened))
+	assert.True(t, strings.HasPrefix(opened[0], server.URL+"/store/nvidia/cart?sku=5438481700"))
+	assert.Equal(t, []string{"5438481700"}, store.Carts())
+
+	polls, err := recorded.Polls(history.Query{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, 24, len(polls))
+
+	failed := 0
+	for _, poll := range polls {
+		if poll.Error != "" {
+			failed++
+		}
+	}
+	assert.Equal(t, 12, failed)
+
+	windows := history.Windows(polls)
+	assert.Equal(t, 1, len(windows))
+	assert.Equal(t, 30*time.Second, windows[0].Start.Sub(polls[0].Time))
+	assert.Equal(t, 70*time.Second, windows[0].Duration(), "failed polls don't end the window")
+	assert.Equal(t, int64(69900), windows[0].Price)
+
+	notifications, _ := recorded.Notifications(history.Query{})
+	assert.Equal(t, 2, len(notifications))
+	assert.Equal(t, alert.KindInStock, notifications[0].Kind)
+	assert.Equal(t, "recording", notifications[0].Channel)
+}
+
+func TestPollerCheckout(t *testing.T) {
+	script, err := fakestore.ParseScript([]byte(`session:
+  - {duration: 10s, status_code: 503}
+  - {status_code: 200}
+products:
+  - sku: 5438481700
+    name: NVIDIA GEFORCE RTX 3080
+    timeline:
+      - {duration: 10s, status: in_stock, price: 699}
+      - {duration: 10s, status: out_of_stock, price: 699}
+      - {status: in_stock, price: 699}
+`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	now := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	store := fakestore.New(*script, func() time.Time { return now })
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	client := rest.NewClient(config.APIConfig{StoreURL: server.URL, APIURL: server.URL}, server.Client())
+	notifier := &recordingNotifier{}
+	filter, _ := ParseFilter(nil)
+
+	watch := config.Watch{Region: "USA", Model: "3080", SKU: "5438481700", Locale: "en_us", NvidiaLocale: "en-us", Currency: "USD", ProductURL: "https://www.nvidia.com/en-us/geforce/graphics-cards/30-series/rtx-3080/"}
+	poller := Poller{
+		Client:    client,
+		Watch:     watch,
+		Alerter:   &Alerter{Name: watch.String(), Filter: filter},
+		Notifiers: []alert.Notifier{notifier},
+		Sessions:  rest.NewSessionManager(client),
+		Remote:    true,
+	}
+
+	// The session API is down so the alert falls back to the product page.
+	assert.Nil(t, poller.Poll(context.Background(), now))
+	assert.Equal(t, 1, len(notifier.events))
+	assert.Equal(t, "https://www.nvidia.com/en-us/geforce/graphics-cards/30-series/rtx-3080/", notifier.events[0].CartURL)
+
+	now = now.Add(10 * time.Second)
+	assert.Nil(t, poller.Poll(context.Background(), now))
+
+	_, err = poller.Sessions.Token(context.Background())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// A rejected session token is refreshed rather than falling back to the product page.
+	store.ExpireSessions()
+
+	now = now.Add(10 * time.Second)
+	assert.Nil(t, poller.Poll(context.Background(), now))
+
+	event := notifier.events[len(notifier.events)-1]
+	assert.Equal(t, alert.KindInStock, event.Kind())
+	assert.True(t, strings.HasPrefix(event.CartURL, server.URL+"/store/nvidia/cart?sku=5438481700"))
+	assert.Equal(t, []string{"5438481700"}, store.Carts())
+}
+
+func TestPollerPartialDelivery(t *testing.T) {
+	script, err := fakestore.ParseScript([]byte(`products:
+  - sku: 5438481700
+    name: NVIDIA GEFORCE RTX 3080
+    timeline:
+      - {duration: 15s, status: in_stock, price: 699}
+      - {duration: 15s, status: out_of_stock, price: 699}
+      - {status: in_stock, price: 699}
+`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	now := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(fakestore.New(*script, func() time.Time { return now }))
+	defer server.Close()
+
+	delivered := &recordingNotifier{}
+	flaky := &failingNotifier{failures: 1}
+	down := &failingNotifier{failures: 100}
+	filter, _ := ParseFilter(nil)
+
+	watch := config.Watch{Region: "USA", Model: "3080", SKU: "5438481700", Locale: "en_us", Currency: "USD"}
+	poller := Poller{
+		Client:    rest.NewClient(config.APIConfig{StoreURL: server.URL, APIURL: server.URL}, server.Client()),
+		Watch:     watch,
+		Alerter:   &Alerter{Name: watch.String(), Filter: filter, Cooldown: time.Minute},
+		Notifiers: []alert.Notifier{delivered, flaky, down},
+		Remote:    true,
+	}
+
+	// Delivering through one notifier starts the cooldown, the others are retried.
+	assert.NotNil(t, poller.Poll(context.Background(), now))
+	assert.Equal(t, 1, len(delivered.events))
+	assert.Equal(t, 0, len(flaky.events))
+	assert.Equal(t, time.Minute, poller.Alerter.Remaining(now))
+
+	// Only the notifiers that failed are retried.
+	now = now.Add(5 * time.Second)
+	assert.NotNil(t, poller.Poll(context.Background(), now))
+	assert.Equal(t, 1, len(delivered.events))
+	assert.Equal(t, 1, len(flaky.events))
+	assert.Equal(t, alert.KindInStock, flaky.events[0].Kind())
+	assert.Equal(t, 2, down.calls)
+
+	now = now.Add(5 * time.Second)
+	assert.NotNil(t, poller.Poll(context.Background(), now))
+	assert.Equal(t, 1, len(flaky.events))
+	assert.Equal(t, 3, down.calls)
+
+	// Going out of stock drops the undelivered alert.
+	now = now.Add(10 * time.Second)
+	assert.Nil(t, poller.Poll(context.Background(), now))
+	assert.Equal(t, 3, down.calls)
+
+	// A restock alerts every notifier again.
+	now = now.Add(15 * time.Second)
+	assert.NotNil(t, poller.Poll(context.Background(), now))
+	assert.Equal(t, 2, len(delivered.events))
+	assert.Equal(t, 2, len(flaky.events))
+	assert.Equal(t, 4, down.calls)
+}
+
+func TestPollerNoDelivery(t *testing.T) {
+	script, err := fakestore.ParseScript([]byte(`products:
+  - sku: 5438481700
+    name: NVIDIA GEFORCE RTX 3080
+    timeline:
+      - {status: in_stock, price: 699}
+`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	now := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(fakestore.New(*script, func() time.Time { return now }))
+	defer server.Close()
+
+	flaky := &failingNotifier{failures: 1}
+	filter, _ := ParseFilter(nil)
+
+	watch := config.Watch{Region: "USA", Model: "3080", SKU: "5438481700", Locale: "en_us", Currency: "USD"}
+	poller := Poller{
+		Client:    rest.NewClient(config.APIConfig{StoreURL: server.URL, APIURL: server.URL}, server.Client()),
+		Watch:     watch,
+		Alerter:   &Alerter{Name: watch.String(), Filter: filter, Cooldown: time.Minute},
+		Notifiers: []alert.Notifier{flaky},
+		Remote:    true,
+	}
+
+	// Without any delivery the cooldown doesn't start and the alert is retried.
+	assert.NotNil(t, poller.Poll(context.Background(), now))
+	assert.Equal(t, time.Duration(0), poller.Alerter.Remaining(now))
+
+	now = now.Add(5 * time.Second)
+	assert.Nil(t, poller.Poll(context.Background(), now))
+	assert.Equal(t, 1, len(flaky.events))
+	assert.Equal(t, time.Minute, poller.Alerter.Remaining(now))
+}
+
+func TestPollerErrors(t *testing.T) {
+	script, err := fakestore.ParseScript([]byte(`products:
+  - sku: 5438481700
+    name: NVIDIA GEFORCE RTX 3080
+    timeline:
+      - {duration: 1m, status_code: 429}
+      - {duration: 1m, status_code: 503}
+      - {status: in_stock, price: 699}
+`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	now := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(fakestore.New(*script, func() time.Time { return now }))
+	defer server.Close()
+
+	filter, _ := ParseFilter(nil)
+	watch := config.Watch{Region: "USA", Model: "3080", SKU: "5438481700", Locale: "en_us", Currency: "USD"}
+	poller := Poller{
+		Client:  rest.NewClient(config.APIConfig{StoreURL: server.URL, APIURL: server.URL}, server.Client()),
+		Watch:   watch,
+		Alerter: &Alerter{Name: watch.String(), Filter: filter},
+		Remote:  true,
+	}
+
+	err = poller.Poll(context.Background(), now)
+	assert.True(t, errors.Is(err, rest.ErrRateLimited))
+	assert.False(t, errors.Is(err, rest.ErrServerError))
+
+	now = now.Add(time.Minute)
+	err = poller.Poll(context.Background(), now)
+	assert.True(t, errors.Is(err, rest.ErrServerError))
+
+	now = now.Add(time.Minute)
+	assert.Nil(t, poller.Poll(context.Background(), now))
+}
+
+func TestPollerCancelled(t *testing.T) {
+	script, err := fakestore.ParseScript([]byte(`products:
+  - sku: 5438481700
+    name: NVIDIA GEFORCE RTX 3080
+    timeline:
+      - {status: in_stock, price: 699}
+`))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	store := fakestore.New(*script, nil)
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := rest.NewClient(config.APIConfig{StoreURL: server.URL, APIURL: server.URL}, &http.Client{Transport: cancellingTransport{cancel}})
+	notifier := &recordingNotifier{}
+	filter, _ := ParseFilter(nil)
+
+	watch := config.Watch{Region: "USA", Model: "3080", SKU: "5438481700", Locale: "en_us", NvidiaLocale: "en-us", Currency: "USD", ProductURL: "https://www.nvidia.com/en-us/geforce/graphics-cards/30-series/rtx-3080/"}
+	poller := Poller{
+		Client:    client,
+		Watch:     watch,
+		Alerter:   &Alerter{Name: watch.String(), Filter: filter},
+		Notifiers: []alert.Notifier{notifier},
+		Sessions:  rest.NewSessionManager(client),
+		Remote:    true,
+	}
+
+	// Shutting down after the product was seen skips the cart but still delivers the alert.
+	assert.Nil(t, poller.Poll(ctx, time.Now()))
+	assert.Equal(t, 1, len(notifier.events))
+	assert.Nil(t, notifier.errs[0])
+	assert.Equal(t, watch.ProductURL, notifier.events[0].CartURL)
+	assert.Equal(t, 0, len(store.Carts()))
+}
+
+func TestPollerNotifyDeadlines(t *testing.T) {
+	release := make(chan struct{})
+	slow := &blockingNotifier{deadline: 30 * time.Second, waits: release}
+	fast := &blockingNotifier{deadline: time.Second, closes: release}
+	poller := Poller{Notifiers: []alert.Notifier{slow, fast}, Remote: true}
+
+	// The first notifier only finishes once the second has been called.
+	_, err := poller.notify(context.Background(), alert.StockEvent{Name: "NVIDIA GEFORCE RTX 3080"}, poller.Notifiers)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 30*time.Second, slow.given, "a retry policy longer than NotifyTimeout gets its own deadline")
+	assert.Equal(t, NotifyTimeout, fast.given)
+}
@@ -0,0 +1,34 @@
+package monitor
+
+import (
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+// PriceTracker follows the sale price of a single watched SKU, the zero value hasn't seen a price yet.
+type PriceTracker struct {
+	pricing rest.Pricing
+	seen    bool
+}
+
+// Observe Records the pricing of a product returning the previous pricing if the sale price changed.
+func (t *PriceTracker) Observe(pricing rest.Pricing) (rest.Pricing, bool) {
+	// A missing price is an API glitch rather than a free graphics card.
+	if pricing.SalePriceWithQuantity.Value <= 0 {
+		return t.pricing, false
+	}
+
+	previous, seen := t.pricing, t.seen
+	t.pricing = pricing
+	t.seen = true
+
+	if seen == false {
+		return previous, false
+	}
+
+	return previous, previous.SalePriceWithQuantity.Value != pricing.SalePriceWithQuantity.Value
+}
+
+// Rollback Restores the pricing from before a change so it is observed again on the next poll.
+func (t *PriceTracker) Rollback(previous rest.Pricing) {
+	t.pricing = previous
+}
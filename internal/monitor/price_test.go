@@ -0,0 +1,46 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+func pricing(value int64) rest.Pricing {
+	return rest.Pricing{SalePriceWithQuantity: rest.Price{Currency: "USD", Value: value}}
+}
+
+func TestPriceTracker(t *testing.T) {
+	tracker := PriceTracker{}
+
+	_, changed := tracker.Observe(pricing(69900))
+	assert.False(t, changed, "first price is not a change")
+
+	_, changed = tracker.Observe(pricing(69900))
+	assert.False(t, changed)
+
+	_, changed = tracker.Observe(pricing(0))
+	assert.False(t, changed, "missing price is ignored")
+
+	previous, changed := tracker.Observe(pricing(64900))
+	assert.True(t, changed)
+	assert.Equal(t, int64(69900), previous.SalePriceWithQuantity.Value)
+
+	tracker.Rollback(previous)
+	previous, changed = tracker.Observe(pricing(64900))
+	assert.True(t, changed)
+	assert.Equal(t, int64(69900), previous.SalePriceWithQuantity.Value)
+}
+
+func TestAlerterPriceChanges(t *testing.T) {
+	alerter := Alerter{Name: "USA/3080"}
+
+	alerter.ObservePrice(pricing(69900))
+	_, changed := alerter.ObservePrice(pricing(64900))
+	assert.False(t, changed, "price changes are disabled by default")
+
+	alerter.PriceChanges = true
+	_, changed = alerter.ObservePrice(pricing(59900))
+	assert.True(t, changed)
+}
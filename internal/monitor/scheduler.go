@@ -0,0 +1,242 @@
+package monitor
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+// Scheduler defaults used when a field is left zero.
+const (
+	DefaultJitter     = config.DefaultJitter
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
+// Metrics published with expvar, keyed by the name of each Scheduler.
+var (
+	intervals = expvar.NewMap("poll_interval_seconds")
+	failures  = expvar.NewMap("poll_failures")
+)
+
+// Scheduler decides how long to wait between polls of NVIDIAs API.
+//
+// After a successful poll it waits Base plus up to Jitter to avoid looking like a bot. When NVIDIA
+// rate limits a request, fails with a 5XX or times out it backs off exponentially with full
+// jitter, waiting Base plus a random part of a window that doubles up to MaxBackoff, and never
+// less than a Retry-After header asks for. The next successful poll returns to the base interval.
+//
+// A Schedule replaces Base and Jitter with the ones in effect at the time, waits after a successful poll are
+// cut short when the schedule changes and polling stops entirely while the schedule is paused.
+type Scheduler struct {
+	Name       string
+	Base       time.Duration
+	Jitter     time.Duration
+	MaxBackoff time.Duration
+	Schedule   *config.Schedule
+
+	backoff  *backoff.ExponentialBackOff
+	rand     *rand.Rand
+	now      func() time.Time
+	failures int
+	interval time.Duration
+
+	// scheduled the delay last announced for the Schedule, -1 when paused.
+	scheduled time.Duration
+	announced bool
+}
+
+// NewScheduler Generates a Scheduler polling every base interval with the default jitter and maximum backoff.
+func NewScheduler(name string, base time.Duration) *Scheduler {
+	return &Scheduler{Name: name, Base: base, Jitter: DefaultJitter, MaxBackoff: DefaultMaxBackoff}
+}
+
+// Next Gets how long to wait before the next poll given the result of the last one.
+func (s *Scheduler) Next(err error) time.Duration {
+	s.init()
+
+	now := s.now()
+	base, polling := s.base(now)
+
+	if polling == false {
+		wait := DefaultMaxBackoff
+		change := s.Schedule.Next(now)
+		if change.IsZero() == false {
+			wait = change.Sub(now)
+		}
+
+		return s.set(wait)
+	}
+
+	if Retryable(err) == false {
+		if s.failures > 0 {
+			log.Println(fmt.Sprintf("[%s] Recovered after %d failed polls, polling every %s", s.Name, s.failures, base))
+		}
+
+		s.failures = 0
+		s.backoff.Reset()
+
+		jitter := s.Jitter
+		if s.Schedule != nil {
+			jitter = s.Schedule.JitterAt(now)
+		}
+
+		wait := base + s.random(jitter)
+		if s.Schedule != nil {
+			change := s.Schedule.Next(now)
+			if change.IsZero() == false && now.Add(wait).After(change) {
+				wait = change.Sub(now)
+			}
+		}
+
+		return s.set(wait)
+	}
+
+	s.failures++
+	failures.Add(s.Name, 1)
+
+	window := s.backoff.NextBackOff()
+	wait := base + s.random(window)
+
+	var httpErr *rest.HTTPError
+	if errors.As(err, &httpErr) {
+		if retryAfter, ok := httpErr.RetryAfter(now); ok && retryAfter > wait {
+			wait = retryAfter
+		}
+	}
+
+	log.Println(fmt.Sprintf("[%s] Backing off for %s after %d failed polls", s.Name, wait.Round(time.Millisecond), s.failures))
+
+	return s.set(wait)
+}
+
+// Interval Gets the most recent wait between polls.
+func (s *Scheduler) Interval() time.Duration {
+	return s.interval
+}
+
+// PeakRate Gets the most polls per second the watches can make at once when each polls at its fastest
+// scheduled delay or every delay without a schedule, ignoring watches polling without a delay. Jitter
+// only ever adds to the delay so the peak is reached when it adds nothing.
+func PeakRate(watches []config.Watch, delay time.Duration) float64 {
+	rate := 0.0
+
+	for _, watch := range watches {
+		fastest, polling := delay, true
+		if watch.Schedule != nil {
+			fastest, polling = watch.Schedule.Fastest()
+		}
+
+		if polling && fastest > 0 {
+			rate += float64(time.Second) / float64(fastest)
+		}
+	}
+
+	return rate
+}
+
+// base Gets the delay between polls in effect at now, false while the Schedule is paused.
+func (s *Scheduler) base(now time.Time) (time.Duration, bool) {
+	if s.Schedule == nil {
+		return s.Base, true
+	}
+
+	delay, polling := s.Schedule.At(now)
+
+	scheduled := delay
+	if polling == false {
+		scheduled = -1
+	}
+
+	if s.announced == false || scheduled != s.scheduled {
+		s.scheduled = scheduled
+		s.announced = true
+
+		until := "further notice"
+		change := s.Schedule.Next(now)
+		if change.IsZero() == false {
+			until = change.In(s.Schedule.Location).Format("Mon 15:04 MST")
+		}
+
+		if polling {
+			log.Println(fmt.Sprintf("[%s] Polling every %s until %s", s.Name, delay, until))
+		} else {
+			log.Println(fmt.Sprintf("[%s] Polling paused until %s", s.Name, until))
+		}
+	}
+
+	return delay, polling
+}
+
+// Retryable Determines if an error is worth backing off for E.X. rate limiting, server errors and timeouts.
+func Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, rest.ErrRateLimited) || errors.Is(err, rest.ErrServerError) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (s *Scheduler) init() {
+	if s.backoff != nil {
+		return
+	}
+
+	if s.MaxBackoff <= 0 {
+		s.MaxBackoff = DefaultMaxBackoff
+	}
+
+	initial := s.Base
+	if initial < time.Second {
+		initial = time.Second
+	}
+
+	s.backoff = &backoff.ExponentialBackOff{
+		InitialInterval:     initial,
+		RandomizationFactor: 0,
+		Multiplier:          2,
+		MaxInterval:         s.MaxBackoff,
+		MaxElapsedTime:      0,
+		Clock:               backoff.SystemClock,
+	}
+	s.backoff.Reset()
+
+	if s.rand == nil {
+		s.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	if s.now == nil {
+		s.now = time.Now
+	}
+}
+
+// random Gets a random duration in [0, max).
+func (s *Scheduler) random(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+
+	return time.Duration(s.rand.Int63n(int64(max)))
+}
+
+func (s *Scheduler) set(interval time.Duration) time.Duration {
+	s.interval = interval
+
+	v := new(expvar.Float)
+	v.Set(interval.Seconds())
+	intervals.Set(s.Name, v)
+
+	return interval
+}
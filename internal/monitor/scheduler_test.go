@@ -0,0 +1,158 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+// timeoutError represents a network timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func newTestScheduler() *Scheduler {
+	s := NewScheduler("USA/3080", time.Second)
+	s.rand = rand.New(rand.NewSource(1))
+
+	return s
+}
+
+func TestSchedulerSuccess(t *testing.T) {
+	s := newTestScheduler()
+
+	for i := 0; i < 20; i++ {
+		wait := s.Next(nil)
+		assert.True(t, wait >= time.Second && wait < time.Second+DefaultJitter, wait)
+		assert.Equal(t, wait, s.Interval())
+	}
+
+	wait := s.Next(&rest.HTTPError{StatusCode: 404})
+	assert.True(t, wait < time.Second+DefaultJitter, "client errors aren't retried with backoff")
+}
+
+func TestSchedulerBackoff(t *testing.T) {
+	s := newTestScheduler()
+	s.MaxBackoff = 16 * time.Second
+
+	errs := []error{
+		&rest.HTTPError{StatusCode: 503},
+		&rest.HTTPError{StatusCode: 429},
+		timeoutError{},
+		&rest.HTTPError{StatusCode: 500},
+		&rest.HTTPError{StatusCode: 502},
+		&rest.HTTPError{StatusCode: 504},
+	}
+
+	window := time.Second
+	for _, err := range errs {
+		wait := s.Next(err)
+		assert.True(t, wait >= time.Second && wait < time.Second+window, "%v waited %s", err, wait)
+
+		if window < s.MaxBackoff {
+			window *= 2
+		}
+	}
+	assert.Equal(t, 6, s.failures)
+
+	wait := s.Next(nil)
+	assert.True(t, wait < time.Second+DefaultJitter, "success returns to the base interval")
+	assert.Equal(t, 0, s.failures)
+
+	wait = s.Next(&rest.HTTPError{StatusCode: 503})
+	assert.True(t, wait < 2*time.Second, "backoff restarts after success")
+}
+
+func TestSchedulerRetryAfter(t *testing.T) {
+	s := newTestScheduler()
+
+	header := http.Header{}
+	header.Set("Retry-After", "120")
+
+	wait := s.Next(&rest.HTTPError{StatusCode: 429, Header: header})
+	assert.Equal(t, 2*time.Minute, wait)
+}
+
+func TestSchedulerSchedule(t *testing.T) {
+	weekdays, _ := config.ParseDays("mon-fri")
+	weekend, _ := config.ParseDays("sat,sun")
+
+	s := newTestScheduler()
+	s.Schedule = &config.Schedule{
+		Location: time.UTC,
+		Delay:    time.Minute,
+		Jitter:   DefaultJitter,
+		Windows: []config.ScheduleWindow{
+			{Days: weekdays, Start: 14 * 60, End: 16 * 60, Delay: 2 * time.Second},
+			{Days: weekend, Start: 0, End: 24 * 60, Paused: true},
+		},
+	}
+
+	// Thursday 2020-09-17.
+	now := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	wait := s.Next(nil)
+	assert.True(t, wait >= time.Minute && wait < time.Minute+DefaultJitter, wait)
+
+	now = time.Date(2020, 9, 17, 13, 59, 30, 0, time.UTC)
+	assert.Equal(t, 30*time.Second, s.Next(nil), "waits are cut short when the schedule changes")
+
+	now = time.Date(2020, 9, 17, 14, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2*time.Second, s.Next(nil), "windows have their own jitter")
+
+	wait = s.Next(&rest.HTTPError{StatusCode: 503})
+	assert.True(t, wait >= 2*time.Second && wait < 3*time.Second, "backoff starts from the scheduled delay")
+
+	// Friday evening polling pauses for the weekend.
+	now = time.Date(2020, 9, 18, 23, 59, 50, 0, time.UTC)
+	assert.Equal(t, 10*time.Second, s.Next(nil))
+
+	now = time.Date(2020, 9, 19, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 48*time.Hour, s.Next(nil))
+	assert.Equal(t, 48*time.Hour, s.Next(&rest.HTTPError{StatusCode: 503}), "no polls while paused")
+}
+
+func TestSchedulerJitter(t *testing.T) {
+	s := newTestScheduler()
+	s.Jitter = 0
+	assert.Equal(t, time.Second, s.Next(nil))
+
+	s.Jitter = 500 * time.Millisecond
+	for i := 0; i < 10; i++ {
+		wait := s.Next(nil)
+		assert.True(t, wait >= time.Second && wait < 1500*time.Millisecond, wait)
+	}
+}
+
+func TestPeakRate(t *testing.T) {
+	weekdays, _ := config.ParseDays("mon-fri")
+	schedule := &config.Schedule{
+		Location: time.UTC,
+		Paused:   true,
+		Windows:  []config.ScheduleWindow{{Days: weekdays, Start: 14 * 60, End: 16 * 60, Delay: 500 * time.Millisecond}},
+	}
+
+	watches := []config.Watch{{Region: "USA"}, {Region: "DEU", Schedule: schedule}, {Region: "AUT", Schedule: &config.Schedule{Paused: true}}}
+	assert.Equal(t, 2.5, PeakRate(watches, 2*time.Second))
+	assert.Equal(t, 2.0, PeakRate(watches, 0), "watches polling without a delay are ignored")
+}
+
+func TestRetryable(t *testing.T) {
+	assert.False(t, Retryable(nil))
+	assert.False(t, Retryable(errors.New("no product information")))
+	assert.False(t, Retryable(&rest.DecodeError{Err: errors.New("unexpected end of JSON input")}))
+	assert.True(t, Retryable(&rest.HTTPError{StatusCode: 429}))
+	assert.True(t, Retryable(&rest.HTTPError{StatusCode: 503}))
+	assert.True(t, Retryable(timeoutError{}))
+	assert.False(t, Retryable(context.Canceled))
+}
@@ -0,0 +1,155 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+)
+
+// State represents the normalized inventory state of a product.
+type State string
+
+// Inventory states a watched product moves between.
+const (
+	Unknown    State = "unknown"
+	InStock    State = "in_stock"
+	OutOfStock State = "out_of_stock"
+	Limited    State = "limited"
+	Backorder  State = "backorder"
+)
+
+// States Gets every inventory state.
+func States() []State {
+	return []State{Unknown, InStock, OutOfStock, Limited, Backorder}
+}
+
+// ParseState Normalizes an inventory status from NVIDIAs API into a State.
+func ParseState(status string) State {
+	switch status {
+	case rest.StatusInStock:
+		return InStock
+	case rest.StatusOutOfStock:
+		return OutOfStock
+	case rest.StatusLimitedAvailability:
+		return Limited
+	case rest.StatusBackorder:
+		return Backorder
+	}
+
+	return Unknown
+}
+
+// Purchasable Determines if a product in this state can be added to a cart.
+func (s State) Purchasable() bool {
+	return s == InStock || s == Limited
+}
+
+// Transition represents a change between two inventory states.
+type Transition struct {
+	From           State
+	To             State
+	PreviousStatus string
+	Status         string
+}
+
+func (t Transition) String() string {
+	return fmt.Sprintf("%s->%s", t.From, t.To)
+}
+
+// Tracker follows the inventory state of a single watched SKU, the zero value starts in the Unknown state.
+type Tracker struct {
+	state  State
+	status string
+}
+
+// State Gets the current inventory state.
+func (t *Tracker) State() State {
+	if t.state == "" {
+		return Unknown
+	}
+
+	return t.state
+}
+
+// Observe Records an inventory status returning the transition if the state changed.
+func (t *Tracker) Observe(status string) (Transition, bool) {
+	next := ParseState(status)
+	transition := Transition{From: t.State(), To: next, PreviousStatus: t.status, Status: status}
+
+	t.state = next
+	t.status = status
+
+	return transition, transition.From != transition.To
+}
+
+// Rollback Restores the state from before a transition so it is observed again on the next poll.
+func (t *Tracker) Rollback(transition Transition) {
+	t.state = transition.From
+	t.status = transition.PreviousStatus
+}
+
+// DefaultTransitions Transitions that trigger alerts when none are configured.
+var DefaultTransitions = []string{"*->in_stock", "*->limited"}
+
+// Filter selects the transitions that trigger alerts.
+type Filter []Transition
+
+// ParseFilter Parses transition patterns E.X. out_of_stock->in_stock where * matches any state.
+func ParseFilter(patterns []string) (Filter, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultTransitions
+	}
+
+	filter := Filter{}
+	problems := []string{}
+
+	for _, pattern := range patterns {
+		parts := strings.Split(pattern, "->")
+		if len(parts) != 2 {
+			problems = append(problems, fmt.Sprintf("%s: transition must look like out_of_stock->in_stock", pattern))
+			continue
+		}
+
+		from, fromOk := parsePattern(parts[0])
+		to, toOk := parsePattern(parts[1])
+		if fromOk == false || toOk == false {
+			problems = append(problems, fmt.Sprintf("%s: states must be * or one of %v", pattern, States()))
+			continue
+		}
+
+		filter = append(filter, Transition{From: from, To: to})
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid transitions: %s", strings.Join(problems, "; "))
+	}
+
+	return filter, nil
+}
+
+func parsePattern(in string) (State, bool) {
+	in = strings.TrimSpace(in)
+	if in == "*" {
+		return "*", true
+	}
+
+	for _, state := range States() {
+		if string(state) == in {
+			return state, true
+		}
+	}
+
+	return "", false
+}
+
+// Match Determines if a transition should trigger an alert.
+func (f Filter) Match(t Transition) bool {
+	for _, pattern := range f {
+		if (pattern.From == "*" || pattern.From == t.From) && (pattern.To == "*" || pattern.To == t.To) {
+			return true
+		}
+	}
+
+	return false
+}
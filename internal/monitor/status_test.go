@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseState(t *testing.T) {
+	assert.Equal(t, InStock, ParseState(rest.StatusInStock))
+	assert.Equal(t, OutOfStock, ParseState(rest.StatusOutOfStock))
+	assert.Equal(t, Limited, ParseState(rest.StatusLimitedAvailability))
+	assert.Equal(t, Backorder, ParseState(rest.StatusBackorder))
+	assert.Equal(t, Unknown, ParseState("PRODUCT_INVENTORY_SOMETHING_NEW"))
+}
+
+func TestTracker(t *testing.T) {
+	tracker := Tracker{}
+	assert.Equal(t, Unknown, tracker.State())
+
+	transition, changed := tracker.Observe(rest.StatusOutOfStock)
+	assert.True(t, changed)
+	assert.Equal(t, "unknown->out_of_stock", transition.String())
+
+	_, changed = tracker.Observe(rest.StatusOutOfStock)
+	assert.False(t, changed)
+
+	transition, changed = tracker.Observe(rest.StatusInStock)
+	assert.True(t, changed)
+	assert.Equal(t, Transition{From: OutOfStock, To: InStock, PreviousStatus: rest.StatusOutOfStock, Status: rest.StatusInStock}, transition)
+
+	tracker.Rollback(transition)
+	assert.Equal(t, OutOfStock, tracker.State())
+
+	_, changed = tracker.Observe(rest.StatusInStock)
+	assert.True(t, changed)
+
+	transition, changed = tracker.Observe(rest.StatusOutOfStock)
+	assert.True(t, changed)
+	assert.Equal(t, "in_stock->out_of_stock", transition.String())
+}
+
+func TestFilter(t *testing.T) {
+	filter, err := ParseFilter(nil)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	assert.True(t, filter.Match(Transition{From: Unknown, To: InStock}))
+	assert.True(t, filter.Match(Transition{From: OutOfStock, To: Limited}))
+	assert.False(t, filter.Match(Transition{From: InStock, To: OutOfStock}))
+
+	filter, err = ParseFilter([]string{"in_stock->*", "out_of_stock->backorder"})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	assert.True(t, filter.Match(Transition{From: InStock, To: OutOfStock}))
+	assert.True(t, filter.Match(Transition{From: OutOfStock, To: Backorder}))
+	assert.False(t, filter.Match(Transition{From: OutOfStock, To: InStock}))
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	_, err := ParseFilter([]string{"in_stock", "sold_out->in_stock"})
+	if err == nil {
+		t.Fatalf("Expected error")
+	}
+
+	assert.Contains(t, err.Error(), "in_stock: transition must look like")
+	assert.Contains(t, err.Error(), "sold_out->in_stock: states must be")
+}
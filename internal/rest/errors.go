@@ -0,0 +1,131 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Errors matched with errors.Is to tell throttling apart from outages.
+var (
+	ErrRateLimited = errors.New("rate limited")
+	ErrServerError = errors.New("server error")
+)
+
+// snippetLength Maximum number of bytes of a response body kept in errors.
+const snippetLength = 256
+
+// HTTPError represents an API response with a status code other than 2XX.
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	message := fmt.Sprintf("%s %s: %s", e.Method, e.URL, e.Status)
+	if e.Body != "" {
+		message = fmt.Sprintf("%s: %s", message, e.Body)
+	}
+
+	return message
+}
+
+// Is Matches ErrRateLimited for 429 responses and ErrServerError for 5XX responses.
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= 500
+	}
+
+	return false
+}
+
+// DecodeError represents a successful API response whose body couldn't be decoded.
+type DecodeError struct {
+	URL  string
+	Body string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding %s: %v: %s", e.URL, e.Err, e.Body)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// CheckResponse Returns an *HTTPError for responses with a status code other than 2XX, consuming and closing their body.
+func CheckResponse(r *http.Response) error {
+	if r.StatusCode >= 200 && r.StatusCode < 300 {
+		return nil
+	}
+	defer r.Body.Close()
+
+	body, _ := ioutil.ReadAll(r.Body)
+
+	e := HTTPError{
+		StatusCode: r.StatusCode,
+		Status:     r.Status,
+		Header:     r.Header,
+		Body:       snippet(body),
+	}
+
+	if e.Status == "" {
+		e.Status = fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode))
+	}
+
+	if r.Request != nil {
+		e.Method = r.Request.Method
+		e.URL = r.Request.URL.String()
+	}
+
+	return &e
+}
+
+// snippet Shortens a response body for use in error messages.
+func snippet(body []byte) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) <= snippetLength {
+		return s
+	}
+
+	s = s[:snippetLength]
+	for len(s) > 0 && utf8.ValidString(s) == false {
+		s = s[:len(s)-1]
+	}
+
+	return s + "..."
+}
+
+// RetryAfter Gets how long the API asked to wait before retrying from the Retry-After header.
+func (e *HTTPError) RetryAfter(now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(e.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if date.Before(now) {
+			return 0, true
+		}
+		return date.Sub(now), true
+	}
+
+	return 0, false
+}
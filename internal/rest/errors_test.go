@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStatusClient(code int, body string) *Client {
+	return NewClient(config.APIConfig{}, NewTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: code,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			Header:     make(http.Header),
+			Request:    req,
+		}
+	}))
+}
+
+func TestGetBodyHTTPErrors(t *testing.T) {
+	tests := map[int]struct {
+		rateLimited bool
+		serverError bool
+	}{
+		302: {false, false},
+		400: {false, false},
+		404: {false, false},
+		429: {true, false},
+		500: {false, true},
+		503: {false, true},
+	}
+
+	for code, expected := range tests {
+		_, err := newStatusClient(code, `{"error": "nope"}`).GetSkuInfo(context.Background(), "5438481700", "en_us", "USD")
+
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) == false {
+			t.Fatalf("%d: expected *HTTPError, got %#v", code, err)
+		}
+
+		assert.Equal(t, code, httpErr.StatusCode)
+		assert.Equal(t, "https://api-prod.nvidia.com/direct-sales-shop/DR/products/en_us/USD/5438481700", httpErr.URL)
+		assert.Equal(t, `{"error": "nope"}`, httpErr.Body)
+		assert.Equal(t, expected.rateLimited, errors.Is(err, ErrRateLimited), code)
+		assert.Equal(t, expected.serverError, errors.Is(err, ErrServerError), code)
+	}
+}
+
+func TestGetBodyDecodeError(t *testing.T) {
+	_, err := newStatusClient(200, `<html>Access Denied</html>`).GetSessionToken(context.Background())
+
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) == false {
+		t.Fatalf("Expected *DecodeError, got %#v", err)
+	}
+
+	assert.Equal(t, "<html>Access Denied</html>", decodeErr.Body)
+	assert.True(t, strings.HasPrefix(decodeErr.URL, "https://store.nvidia.com/store/nvidia/SessionToken"))
+	assert.False(t, errors.Is(err, ErrServerError))
+}
+
+func TestHTTPErrorSnippet(t *testing.T) {
+	_, err := newStatusClient(503, strings.Repeat("€", 200)).GetProducts(context.Background(), "de_de", "EUR")
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) == false {
+		t.Fatalf("Expected *HTTPError, got %#v", err)
+	}
+
+	assert.True(t, len(httpErr.Body) <= snippetLength+3)
+	assert.True(t, strings.HasSuffix(httpErr.Body, "€..."))
+	assert.Equal(t, "GET https://api-prod.nvidia.com/direct-sales-shop/DR/products/de_de/EUR: 503 Service Unavailable: "+httpErr.Body, err.Error())
+}
+
+func TestHTTPErrorRetryAfter(t *testing.T) {
+	now := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"30":                            30 * time.Second,
+		"Thu, 17 Sep 2020 13:01:00 GMT": time.Minute,
+		"Thu, 17 Sep 2020 12:59:00 GMT": 0,
+	}
+
+	for value, expected := range tests {
+		header := http.Header{}
+		header.Set("Retry-After", value)
+
+		retryAfter, ok := (&HTTPError{Header: header}).RetryAfter(now)
+		assert.True(t, ok, value)
+		assert.Equal(t, expected, retryAfter, value)
+	}
+
+	_, ok := (&HTTPError{Header: http.Header{}}).RetryAfter(now)
+	assert.False(t, ok)
+
+	header := http.Header{}
+	header.Set("Retry-After", "soon")
+	_, ok = (&HTTPError{Header: header}).RetryAfter(now)
+	assert.False(t, ok)
+}
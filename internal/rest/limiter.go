@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter represents a token bucket per host shared by every request of the process.
+//
+// Each host accrues Rate tokens per second up to Burst, and every request spends one token,
+// waiting for it when none are left. A zero Rate disables limiting.
+type Limiter struct {
+	Rate  float64
+	Burst int
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter Generates a Limiter allowing rate requests per second to each host with bursts of up to one second of requests.
+func NewLimiter(rate float64) *Limiter {
+	burst := int(rate)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{Rate: rate, Burst: burst}
+}
+
+// Wait Blocks until a request to host is allowed or ctx is done, returning how long it had to wait.
+//
+// A wait cut short by ctx gives its token back and returns the error of ctx, the request must not be sent.
+func (l *Limiter) Wait(ctx context.Context, host string) (time.Duration, error) {
+	wait := l.reserve(host)
+	if wait <= 0 {
+		return 0, nil
+	}
+
+	err := l.sleep(ctx, wait)
+	if err != nil {
+		l.refund(host)
+		return wait, err
+	}
+
+	return wait, nil
+}
+
+// reserve Spends a token for host returning how long until it is available.
+func (l *Limiter) reserve(host string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.Rate <= 0 {
+		return 0
+	}
+
+	if l.buckets == nil {
+		l.buckets = map[string]*bucket{}
+	}
+	if l.now == nil {
+		l.now = time.Now
+	}
+	if l.sleep == nil {
+		l.sleep = Sleep
+	}
+
+	burst := float64(l.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	now := l.now()
+	b, ok := l.buckets[host]
+	if ok == false {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[host] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * l.Rate
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+
+	// Tokens go negative while requests queue up so later callers wait behind earlier ones.
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / l.Rate * float64(time.Second))
+}
+
+// refund Returns a token reserved for host that was never used, shortening the wait of requests queued behind it.
+func (l *Limiter) refund(host string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[host]
+	if ok == false {
+		return
+	}
+
+	b.tokens++
+	if burst := float64(l.Burst); b.tokens > burst && burst >= 1 {
+		b.tokens = burst
+	}
+}
+
+// Sleep Pauses for d or until ctx is done, returning the error of ctx if it finished first.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,163 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestLimiter returns a Limiter whose clock only moves when it sleeps.
+func newTestLimiter(rate float64, burst int) (*Limiter, *time.Duration) {
+	start := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	slept := time.Duration(0)
+
+	l := &Limiter{Rate: rate, Burst: burst}
+	l.now = func() time.Time { return start.Add(slept) }
+	l.sleep = func(ctx context.Context, d time.Duration) error {
+		slept += d
+		return nil
+	}
+
+	return l, &slept
+}
+
+// wait Calls Wait expecting it to succeed, returning how long it waited.
+func wait(t *testing.T, l *Limiter, host string) time.Duration {
+	d, err := l.Wait(context.Background(), host)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	return d
+}
+
+func TestLimiterWait(t *testing.T) {
+	l, slept := newTestLimiter(2, 1)
+
+	assert.Equal(t, time.Duration(0), wait(t, l, "api-prod.nvidia.com"))
+	assert.Equal(t, 500*time.Millisecond, wait(t, l, "api-prod.nvidia.com"))
+	assert.Equal(t, 500*time.Millisecond, wait(t, l, "api-prod.nvidia.com"))
+	assert.Equal(t, time.Second, *slept)
+
+	assert.Equal(t, time.Duration(0), wait(t, l, "store.nvidia.com"), "hosts have separate buckets")
+}
+
+func TestLimiterBurst(t *testing.T) {
+	l, slept := newTestLimiter(1, 3)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, time.Duration(0), wait(t, l, "api-prod.nvidia.com"))
+	}
+	assert.Equal(t, time.Second, wait(t, l, "api-prod.nvidia.com"))
+
+	*slept += time.Hour
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, time.Duration(0), wait(t, l, "api-prod.nvidia.com"), "tokens refill up to the burst")
+	}
+	assert.Equal(t, time.Second, wait(t, l, "api-prod.nvidia.com"))
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	l, _ := newTestLimiter(0, 0)
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, time.Duration(0), wait(t, l, "api-prod.nvidia.com"))
+	}
+}
+
+func TestLimiterRefund(t *testing.T) {
+	l, _ := newTestLimiter(1, 1)
+	l.sleep = func(ctx context.Context, d time.Duration) error {
+		return context.Canceled
+	}
+
+	assert.Equal(t, time.Duration(0), wait(t, l, "api-prod.nvidia.com"))
+
+	_, err := l.Wait(context.Background(), "api-prod.nvidia.com")
+	assert.Equal(t, context.Canceled, err)
+
+	// The abandoned request gave its token back so the next one only waits behind the first.
+	d, _ := l.Wait(context.Background(), "api-prod.nvidia.com")
+	assert.Equal(t, time.Second, d)
+}
+
+func TestClientLimiter(t *testing.T) {
+	l, slept := newTestLimiter(10, 1)
+
+	api := NewClient(config.APIConfig{}, NewTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"session_token": "12345"}`)),
+			Header:     make(http.Header),
+		}
+	}))
+	api.Limiter = l
+
+	for i := 0; i < 5; i++ {
+		_, err := api.GetSessionToken(context.Background())
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	assert.Equal(t, 400*time.Millisecond, *slept)
+}
+
+func TestClientLimiterTimeout(t *testing.T) {
+	l := &Limiter{Rate: 10, Burst: 1}
+
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"session_token": "12345"}`)),
+			Header:     make(http.Header),
+		}
+	})
+	client.Timeout = 50 * time.Millisecond
+
+	api := NewClient(config.APIConfig{}, client)
+	api.Limiter = l
+
+	// The second request queues for 100ms, longer than the client timeout which only starts once it is sent.
+	for i := 0; i < 2; i++ {
+		_, err := api.GetSessionToken(context.Background())
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+}
+
+func TestClientLimiterCancelled(t *testing.T) {
+	l, _ := newTestLimiter(0.001, 1)
+	l.sleep = Sleep
+
+	sent := 0
+	api := NewClient(config.APIConfig{}, NewTestClient(func(req *http.Request) *http.Response {
+		sent++
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"session_token": "12345"}`)),
+			Header:     make(http.Header),
+		}
+	}))
+	api.Limiter = l
+
+	_, err := api.GetSessionToken(context.Background())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = api.GetSessionToken(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "queued request is abandoned once its context is done")
+	assert.Equal(t, 1, sent)
+}
@@ -2,12 +2,24 @@ package rest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+)
+
+// Inventory statuses reported by NVIDIAs API.
+const (
+	StatusInStock             = "PRODUCT_INVENTORY_IN_STOCK"
+	StatusOutOfStock          = "PRODUCT_INVENTORY_OUT_OF_STOCK"
+	StatusLimitedAvailability = "PRODUCT_INVENTORY_LIMITED_AVAILABILITY"
+	StatusBackorder           = "PRODUCT_INVENTORY_BACKORDER"
 )
 
 // ProductsResponse Used for unmarshalling JSON response from api.nvidia.partners
@@ -110,24 +122,65 @@ type AddToCartResponse struct {
 	URL string `json:"location"`
 }
 
+// Default endpoints of NVIDIAs store and product APIs.
+const (
+	DefaultStoreURL  = "https://store.nvidia.com"
+	DefaultAPIURL    = "https://api-prod.nvidia.com"
+	DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/85.0.4183.121 Safari/537.36"
+)
+
+// Client represents a client for NVIDIAs store and product APIs.
+type Client struct {
+	StoreURL  string
+	APIURL    string
+	UserAgent string
+	HTTP      *http.Client
+
+	// Limiter queues requests per host before they are sent so waiting doesn't count towards the timeout of HTTP, nil disables it.
+	Limiter *Limiter
+}
+
+// NewClient Generates a Client for the configured API endpoints, empty values use NVIDIAs production APIs.
+func NewClient(cfg config.APIConfig, client *http.Client) *Client {
+	c := Client{
+		StoreURL:  strings.TrimSuffix(cfg.StoreURL, "/"),
+		APIURL:    strings.TrimSuffix(cfg.APIURL, "/"),
+		UserAgent: cfg.UserAgent,
+		HTTP:      client,
+	}
+
+	if c.StoreURL == "" {
+		c.StoreURL = DefaultStoreURL
+	}
+
+	if c.APIURL == "" {
+		c.APIURL = DefaultAPIURL
+	}
+
+	if c.UserAgent == "" {
+		c.UserAgent = DefaultUserAgent
+	}
+
+	return &c
+}
+
 //GetSessionToken Retrieves the session token for NVIDIA store.
-func GetSessionToken(client *http.Client) (*SessionToken, error) {
-	url := "https://store.nvidia.com/store/nvidia/SessionToken?format=json" + urlTime()
+func (c *Client) GetSessionToken(ctx context.Context) (*SessionToken, error) {
+	url := c.StoreURL + "/store/nvidia/SessionToken?format=json" + urlTime()
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", getUserAgent())
 
-	resBody, err := getBody(req, client)
+	resBody, err := c.getBody(req)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
 	session := SessionToken{}
-	jsonErr := json.Unmarshal(resBody, &session)
+	jsonErr := decode(req, resBody, &session)
 	if jsonErr != nil {
 		log.Println(jsonErr)
 		return nil, jsonErr
@@ -137,10 +190,10 @@ func GetSessionToken(client *http.Client) (*SessionToken, error) {
 }
 
 //AddToCheckout Adds a product to a checkout cart for NVIDIA store.
-func AddToCheckout(sku string, token string, locale string, client *http.Client) (*AddToCartResponse, error) {
-	url := "https://api-prod.nvidia.com/direct-sales-shop/DR/add-to-cart"
+func (c *Client) AddToCheckout(ctx context.Context, sku string, token string, locale string) (*AddToCartResponse, error) {
+	url := c.APIURL + "/direct-sales-shop/DR/add-to-cart"
 	reqBody := []byte(fmt.Sprintf(`{"products": [{"productId":%s,"quantity": 1}]}`, sku))
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, err
 	}
@@ -149,14 +202,14 @@ func AddToCheckout(sku string, token string, locale string, client *http.Client)
 	req.Header.Set("nvidia_shop_id", token)
 	req.Header.Add("charset", "utf-8")
 
-	resBody, err := getBody(req, client)
+	resBody, err := c.getBody(req)
 	if err != nil {
 		return nil, err
 	}
 
 	cart := AddToCartResponse{}
 
-	jsonErr := json.Unmarshal(resBody, &cart)
+	jsonErr := decode(req, resBody, &cart)
 	if jsonErr != nil {
 		return nil, jsonErr
 	}
@@ -167,22 +220,22 @@ func AddToCheckout(sku string, token string, locale string, client *http.Client)
 }
 
 // GetSkuInfo Looks up SKU invormation from NVIDIAs API.
-func GetSkuInfo(sku string, locale string, currency string, client *http.Client) (*ProductsResponse, error) {
-	url := fmt.Sprintf("https://api-prod.nvidia.com/direct-sales-shop/DR/products/%s/%s/%s", locale, currency, sku)
+func (c *Client) GetSkuInfo(ctx context.Context, sku string, locale string, currency string) (*ProductsResponse, error) {
+	url := fmt.Sprintf("%s/direct-sales-shop/DR/products/%s/%s/%s", c.APIURL, locale, currency, sku)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resBody, err := getBody(req, client)
+	resBody, err := c.getBody(req)
 	if err != nil {
 		log.Println(err)
 		return nil, err
 	}
 
 	products := ProductsResponse{}
-	jsonErr := json.Unmarshal(resBody, &products)
+	jsonErr := decode(req, resBody, &products)
 	if jsonErr != nil {
 		log.Println(jsonErr)
 		return nil, jsonErr
@@ -191,18 +244,49 @@ func GetSkuInfo(sku string, locale string, currency string, client *http.Client)
 	return &products, nil
 }
 
-func getUserAgent() string {
-	agent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/85.0.4183.121 Safari/537.36"
-	return agent
+// GetProducts Looks up every product sold for a locale and currency from NVIDIAs API.
+func (c *Client) GetProducts(ctx context.Context, locale string, currency string) (*ProductsResponse, error) {
+	url := fmt.Sprintf("%s/direct-sales-shop/DR/products/%s/%s", c.APIURL, locale, currency)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := c.getBody(req)
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	products := ProductsResponse{}
+	jsonErr := decode(req, resBody, &products)
+	if jsonErr != nil {
+		log.Println(jsonErr)
+		return nil, jsonErr
+	}
+
+	return &products, nil
 }
 
 // Gets the byte data out of a request body.
-func getBody(request *http.Request, client *http.Client) ([]byte, error) {
-	r, err := client.Do(request)
+func (c *Client) getBody(request *http.Request) ([]byte, error) {
+	request.Header.Set("User-Agent", c.UserAgent)
+
+	if c.Limiter != nil {
+		_, err := c.Limiter.Wait(request.Context(), request.URL.Host)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := c.HTTP.Do(request)
 	if err != nil {
 		return nil, err
 	}
-	if r.StatusCode > 400 {
+
+	err = CheckResponse(r)
+	if err != nil {
 		return nil, err
 	}
 	defer r.Body.Close()
@@ -215,6 +299,16 @@ func getBody(request *http.Request, client *http.Client) ([]byte, error) {
 	return body, nil
 }
 
+// decode Unmarshals a JSON response body into v returning a *DecodeError on failure.
+func decode(request *http.Request, body []byte, v interface{}) error {
+	err := json.Unmarshal(body, v)
+	if err != nil {
+		return &DecodeError{URL: request.URL.String(), Body: snippet(body), Err: err}
+	}
+
+	return nil
+}
+
 //urlTime Generates a url encoded datetime parameter used for cache invalidation in browsers.
 func urlTime() string {
 	sec := time.Now().Unix()
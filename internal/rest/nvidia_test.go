@@ -2,11 +2,15 @@ package rest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"testing"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestGetSessionToken(t *testing.T) {
@@ -29,7 +33,7 @@ func TestGetSessionToken(t *testing.T) {
 		}
 	})
 
-	sessionToken, err := GetSessionToken(client)
+	sessionToken, err := NewClient(config.APIConfig{}, client).GetSessionToken(context.Background())
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -38,3 +42,47 @@ func TestGetSessionToken(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 }
+
+func TestClientBaseURLs(t *testing.T) {
+	requests := []string{}
+
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		requests = append(requests, req.URL.String())
+		assert.Equal(t, "clerk-test", req.Header.Get("User-Agent"))
+
+		body := `{"products": {"product": [{"id": 5438481700, "name": "NVIDIA GEFORCE RTX 3080"}]}}`
+		if strings.Contains(req.URL.Path, "SessionToken") {
+			body = `{"session_token": "12345"}`
+		}
+
+		return &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	api := NewClient(config.APIConfig{StoreURL: "http://localhost:8080/", APIURL: "http://localhost:8081", UserAgent: "clerk-test"}, client)
+
+	_, err := api.GetSessionToken(context.Background())
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+
+	info, err := api.GetSkuInfo(context.Background(), "5438481700", "en_us", "USD")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, "NVIDIA GEFORCE RTX 3080", info.Products.Product[0].Name)
+
+	assert.True(t, strings.HasPrefix(requests[0], "http://localhost:8080/store/nvidia/SessionToken?format=json"))
+	assert.Equal(t, "http://localhost:8081/direct-sales-shop/DR/products/en_us/USD/5438481700", requests[1])
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	api := NewClient(config.APIConfig{}, http.DefaultClient)
+
+	assert.Equal(t, DefaultStoreURL, api.StoreURL)
+	assert.Equal(t, DefaultAPIURL, api.APIURL)
+	assert.Equal(t, DefaultUserAgent, api.UserAgent)
+}
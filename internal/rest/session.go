@@ -0,0 +1,156 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Session refresh defaults used when a field is left zero.
+const (
+	DefaultSessionTTL   = 10 * time.Minute
+	DefaultSessionRetry = 10 * time.Second
+)
+
+// SessionManager keeps an NVIDIA store session token fresh and shares it safely between goroutines.
+//
+// Tokens are refreshed on a schedule by Run, on demand by Token once they are older than TTL,
+// and straight away when the store rejects them while adding a product to a cart.
+type SessionManager struct {
+	Client *Client
+	TTL    time.Duration
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+	now     func() time.Time
+}
+
+// NewSessionManager Generates a SessionManager for a Client using the default token lifetime.
+func NewSessionManager(client *Client) *SessionManager {
+	return &SessionManager{Client: client, TTL: DefaultSessionTTL}
+}
+
+// Token Gets the current session token, fetching a new one when there is none or it has expired.
+func (m *SessionManager) Token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token != "" && m.clock().Before(m.expires) {
+		return m.token, nil
+	}
+
+	return m.refresh(ctx)
+}
+
+// Expires Gets when the current session token expires, zero when there is no token.
+func (m *SessionManager) Expires() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.token == "" {
+		return time.Time{}
+	}
+
+	return m.expires
+}
+
+// Refresh Fetches a new session token even if the current one hasn't expired.
+func (m *SessionManager) Refresh(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.refresh(ctx)
+}
+
+// Invalidate Discards a rejected session token so the next call to Token fetches a new one.
+func (m *SessionManager) Invalidate(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Another goroutine may already have replaced the rejected token.
+	if m.token == token {
+		m.token = ""
+	}
+}
+
+// AddToCheckout Adds a product to a checkout cart with the managed session token, retrying once with a new token if it was rejected.
+func (m *SessionManager) AddToCheckout(ctx context.Context, sku string, locale string) (*AddToCartResponse, error) {
+	token, err := m.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := m.Client.AddToCheckout(ctx, sku, token, locale)
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized || httpErr.StatusCode == http.StatusForbidden) {
+		log.Println("NVIDIA rejected the session token refreshing...")
+		m.Invalidate(token)
+
+		token, err = m.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return m.Client.AddToCheckout(ctx, sku, token, locale)
+	}
+
+	return cart, err
+}
+
+// Run Refreshes the session token every half of its lifetime, retrying sooner after failures, until ctx is done.
+func (m *SessionManager) Run(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		wait := m.ttl() / 2
+
+		_, err := m.Refresh(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Println(fmt.Sprintf("Error getting session token from NVIDIA retrying in %s...", DefaultSessionRetry))
+			wait = DefaultSessionRetry
+		}
+
+		if Sleep(ctx, wait) != nil {
+			return
+		}
+	}
+}
+
+// refresh Fetches a new session token, the caller must hold mu.
+func (m *SessionManager) refresh(ctx context.Context) (string, error) {
+	session, err := m.Client.GetSessionToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if session.Value == "" {
+		return "", fmt.Errorf("empty session token from %s", m.Client.StoreURL)
+	}
+
+	m.token = session.Value
+	m.expires = m.clock().Add(m.ttl())
+
+	return m.token, nil
+}
+
+func (m *SessionManager) ttl() time.Duration {
+	if m.TTL <= 0 {
+		return DefaultSessionTTL
+	}
+
+	return m.TTL
+}
+
+func (m *SessionManager) clock() time.Time {
+	if m.now == nil {
+		return time.Now()
+	}
+
+	return m.now()
+}
@@ -0,0 +1,165 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ianmarmour/nvidia-clerk/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStore represents a stand-in NVIDIA store issuing numbered session tokens.
+type testStore struct {
+	mu       sync.Mutex
+	issued   int
+	valid    map[string]bool
+	failures int
+}
+
+func newTestSessionManager(store *testStore) *SessionManager {
+	client := NewTestClient(func(req *http.Request) *http.Response {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+
+		code, body := 200, ""
+
+		switch {
+		case strings.Contains(req.URL.Path, "SessionToken") && store.failures > 0:
+			store.failures--
+			code = 503
+		case strings.Contains(req.URL.Path, "SessionToken"):
+			store.issued++
+			token := fmt.Sprintf("token-%d", store.issued)
+			store.valid[token] = true
+			body = fmt.Sprintf(`{"session_token": "%s"}`, token)
+		case strings.Contains(req.URL.Path, "add-to-cart") && store.valid[req.Header.Get("nvidia_shop_id")] == false:
+			code = 401
+		default:
+			body = `{"location": "https://store.nvidia.com/store/nvidia/cart"}`
+		}
+
+		return &http.Response{
+			StatusCode: code,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			Header:     make(http.Header),
+		}
+	})
+
+	return NewSessionManager(NewClient(config.APIConfig{}, client))
+}
+
+func TestSessionManagerToken(t *testing.T) {
+	store := &testStore{valid: map[string]bool{}}
+	m := newTestSessionManager(store)
+
+	now := time.Date(2020, 9, 17, 13, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	assert.True(t, m.Expires().IsZero())
+
+	token, err := m.Token(context.Background())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, "token-1", token)
+	assert.Equal(t, now.Add(DefaultSessionTTL), m.Expires())
+
+	token, _ = m.Token(context.Background())
+	assert.Equal(t, "token-1", token, "token is reused until it expires")
+
+	now = now.Add(DefaultSessionTTL)
+	token, _ = m.Token(context.Background())
+	assert.Equal(t, "token-2", token, "expired token is refreshed")
+
+	token, _ = m.Refresh(context.Background())
+	assert.Equal(t, "token-3", token)
+
+	m.Invalidate("token-2")
+	token, _ = m.Token(context.Background())
+	assert.Equal(t, "token-3", token, "invalidating a replaced token does nothing")
+
+	m.Invalidate("token-3")
+	token, _ = m.Token(context.Background())
+	assert.Equal(t, "token-4", token)
+}
+
+func TestSessionManagerTokenError(t *testing.T) {
+	store := &testStore{valid: map[string]bool{}, failures: 1}
+	m := newTestSessionManager(store)
+
+	_, err := m.Token(context.Background())
+	assert.NotNil(t, err)
+
+	token, err := m.Token(context.Background())
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, "token-1", token)
+}
+
+func TestSessionManagerAddToCheckout(t *testing.T) {
+	store := &testStore{valid: map[string]bool{}}
+	m := newTestSessionManager(store)
+
+	cart, err := m.AddToCheckout(context.Background(), "5438481700", "en-us")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.True(t, strings.HasPrefix(cart.URL, "https://store.nvidia.com/store/nvidia/cart"))
+
+	store.mu.Lock()
+	store.valid = map[string]bool{}
+	store.mu.Unlock()
+
+	_, err = m.AddToCheckout(context.Background(), "5438481700", "en-us")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	assert.Equal(t, 2, store.issued, "rejected token is refreshed once")
+}
+
+func TestSessionManagerConcurrent(t *testing.T) {
+	store := &testStore{valid: map[string]bool{}}
+	m := newTestSessionManager(store)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			token, err := m.Token(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, "token-1", token)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, store.issued)
+}
+
+func TestSessionManagerRun(t *testing.T) {
+	store := &testStore{valid: map[string]bool{}}
+	m := newTestSessionManager(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go m.Run(ctx, &wg)
+
+	for m.Expires().IsZero() {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	wg.Wait()
+
+	assert.Equal(t, 1, store.issued)
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -31,13 +32,6 @@ func (r *shieldsResponse) JSON() ([]byte, error) {
 	return payload, nil
 }
 
-var client *http.Client
-
-// init Initializes required variables.
-func init() {
-	client = &http.Client{Timeout: 10 * time.Second}
-}
-
 func newShieldsResponse() shieldsResponse {
 	return shieldsResponse{
 		Version:   1,
@@ -47,9 +41,9 @@ func newShieldsResponse() shieldsResponse {
 	}
 }
 
-func getShieldsResponse() []byte {
+func getShieldsResponse(ctx context.Context, client *Client) []byte {
 	res := newShieldsResponse()
-	_, err := GetSessionToken(client)
+	_, err := client.GetSessionToken(ctx)
 	if err != nil {
 		res.Message = "offline"
 	}
@@ -65,15 +59,49 @@ func getShieldsResponse() []byte {
 	return json
 }
 
-func endpoint(w http.ResponseWriter, r *http.Request) {
-	fmt.Fprintf(w, string(getShieldsResponse()))
+func endpoint(client *Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, string(getShieldsResponse(r.Context(), client)))
+	}
 }
 
-//StartShieldsAPIServer Starts up a shields API server
-func StartShieldsAPIServer(config config.ShieldsConfig, wg *sync.WaitGroup) {
+// ShutdownTimeout Maximum time given to in-flight requests once a server is asked to stop.
+const ShutdownTimeout = 10 * time.Second
+
+//StartShieldsAPIServer Starts up a shields API server, shutting it down gracefully once ctx is done.
+func StartShieldsAPIServer(ctx context.Context, config config.ShieldsConfig, client *Client, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	router := mux.NewRouter().StrictSlash(true)
-	router.HandleFunc("/endpoint", endpoint)
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", config.Port), router))
+	router.HandleFunc("/endpoint", endpoint(client))
+
+	err := Serve(ctx, &http.Server{Addr: fmt.Sprintf(":%s", config.Port), Handler: router})
+	if err != nil {
+		log.Println(fmt.Sprintf("Error serving shields API: %v", err))
+	}
+}
+
+// Serve Runs a HTTP server until ctx is done, then waits up to ShutdownTimeout for in-flight requests to finish.
+func Serve(ctx context.Context, server *http.Server) error {
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
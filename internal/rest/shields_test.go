@@ -0,0 +1,33 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- Serve(ctx, &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()})
+	}()
+
+	cancel()
+
+	select {
+	case err := <-errs:
+		assert.Nil(t, err)
+	case <-time.After(ShutdownTimeout):
+		t.Fatalf("Serve didn't return after its context was cancelled")
+	}
+}
+
+func TestServeError(t *testing.T) {
+	err := Serve(context.Background(), &http.Server{Addr: "127.0.0.1:-1"})
+	assert.NotNil(t, err)
+}
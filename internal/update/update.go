@@ -1,6 +1,7 @@
 package update
 
 import (
+	"context"
 	"log"
 	"math/rand"
 	"net/http"
@@ -10,19 +11,28 @@ import (
 	"github.com/inconshreveable/go-update"
 )
 
-// FetchApply Fetches and applys any updates from GitHub releases to this program in place.
-func FetchApply(url string, wg *sync.WaitGroup) error {
+// FetchApply Fetches and applys any updates from GitHub releases to this program in place until ctx is done.
+func FetchApply(ctx context.Context, url string, wg *sync.WaitGroup) error {
 	defer wg.Done()
 
 	for {
 		log.Println("Attempting to fetch updates from github")
-		doUpdate(url)
-		sleep(60000)
+		doUpdate(ctx, url)
+
+		err := sleep(ctx, 60000)
+		if err != nil {
+			return err
+		}
 	}
 }
 
-func doUpdate(url string) error {
-	resp, err := http.Get(url)
+func doUpdate(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -35,12 +45,21 @@ func doUpdate(url string) error {
 	return err
 }
 
-func sleep(delay int64) {
+func sleep(ctx context.Context, delay int64) error {
 	// Force a randomized jitter of up to 5 seconds to avoid looking like a bot.
 	rand.Seed(time.Now().UnixNano())
 	n := rand.Intn(5)
 
 	ns := time.Duration(n) * time.Second
 	ds := time.Duration(delay/1000) * time.Second
-	time.Sleep(time.Duration(ns + ds))
+
+	timer := time.NewTimer(time.Duration(ns + ds))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }